@@ -0,0 +1,302 @@
+// Package auth checks that transaction envelopes carry enough signatures to
+// satisfy the thresholds of their source accounts.
+//
+// Every signature of an envelope is matched to the signers of the source
+// accounts using its hint and then verified against the transaction hash.
+// The weights of the verified signers are summed per source account and
+// compared to the threshold required by the transaction and each of its
+// operations.  Signers are restricted by their type: admin signers only count
+// towards administrative operations, emission signers only towards payments
+// and general signers towards everything but administrative operations.
+package auth
+
+import (
+	"fmt"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/horizon"
+	"bitbucket.org/atticlab/go-smart-base/keypair"
+	"bitbucket.org/atticlab/go-smart-base/network"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// Signer is a key allowed to sign on behalf of an account.
+type Signer struct {
+	Address string
+	Weight  int32
+	Type    xdr.SignerType
+}
+
+// Account describes the signing configuration of a source account.  The
+// master key, when it has a weight, is listed as one of the signers.
+type Account struct {
+	Address    string
+	Thresholds xdr.Thresholds
+	Signers    []Signer
+}
+
+// AccountFromEntry returns the signing configuration of the provided ledger
+// entry.
+func AccountFromEntry(entry xdr.AccountEntry) Account {
+	ret := Account{
+		Address:    entry.AccountId.Address(),
+		Thresholds: entry.Thresholds,
+	}
+
+	master := entry.Thresholds[xdr.ThresholdIndexesThresholdMasterWeight]
+	if master > 0 {
+		ret.Signers = append(ret.Signers, Signer{
+			Address: ret.Address,
+			Weight:  int32(master),
+			Type:    xdr.SignerTypeSignerGeneral,
+		})
+	}
+
+	for _, signer := range entry.Signers {
+		ret.Signers = append(ret.Signers, Signer{
+			Address: signer.PubKey.Address(),
+			Weight:  int32(signer.Weight),
+			Type:    xdr.SignerType(signer.SignerType),
+		})
+	}
+
+	return ret
+}
+
+// AccountFromHorizon returns the signing configuration of the provided
+// horizon account.
+func AccountFromHorizon(account horizon.Account) Account {
+	ret := Account{Address: account.AccountID}
+	ret.Thresholds[xdr.ThresholdIndexesThresholdLow] = account.Thresholds.LowThreshold
+	ret.Thresholds[xdr.ThresholdIndexesThresholdMed] = account.Thresholds.MedThreshold
+	ret.Thresholds[xdr.ThresholdIndexesThresholdHigh] = account.Thresholds.HighThreshold
+
+	for _, signer := range account.Signers {
+		if signer.Weight <= 0 {
+			continue
+		}
+
+		if signer.PublicKey == ret.Address {
+			ret.Thresholds[xdr.ThresholdIndexesThresholdMasterWeight] = byte(signer.Weight)
+		}
+
+		ret.Signers = append(ret.Signers, Signer{
+			Address: signer.PublicKey,
+			Weight:  signer.Weight,
+			Type:    xdr.SignerType(signer.SignerType),
+		})
+	}
+
+	return ret
+}
+
+// Requirement is the outcome of checking a single threshold of a source
+// account.
+type Requirement struct {
+	Source string
+	Level  xdr.ThresholdIndexes
+	Needed int32
+	Weight int32
+}
+
+// Met returns true if the signatures provide enough weight to meet the
+// threshold.
+func (r Requirement) Met() bool {
+	return r.Weight >= r.Needed
+}
+
+// Result is the outcome of checking an envelope.  Transaction holds the
+// requirement of the transaction's source account, Operations holds the
+// requirement of each operation, in order.  UnusedSignatures holds the
+// indexes of the envelope's signatures that did not count towards any
+// requirement.
+type Result struct {
+	Transaction      Requirement
+	Operations       []Requirement
+	UnusedSignatures []int
+}
+
+// Authorized returns true if every requirement is met and every signature
+// was used, in which case the network will accept the envelope's signatures.
+func (r *Result) Authorized() bool {
+	if !r.Transaction.Met() || len(r.UnusedSignatures) > 0 {
+		return false
+	}
+
+	for _, op := range r.Operations {
+		if !op.Met() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Checker checks envelopes against a set of known accounts.
+type Checker struct {
+	NetworkPassphrase string
+	Accounts          map[string]Account
+}
+
+// NewChecker returns a checker for the provided network that knows about the
+// provided accounts.
+func NewChecker(networkPassphrase string, accounts ...Account) *Checker {
+	c := &Checker{
+		NetworkPassphrase: networkPassphrase,
+		Accounts:          map[string]Account{},
+	}
+
+	for _, account := range accounts {
+		c.Accounts[account.Address] = account
+	}
+
+	return c
+}
+
+// Check verifies the signatures of the provided envelope and reports which
+// thresholds they meet.  An error is returned if the signing configuration of
+// a source account is unknown.
+func (c *Checker) Check(e *xdr.TransactionEnvelope) (*Result, error) {
+	tx := build.TransactionBuilder{
+		TX:        &e.Tx,
+		NetworkID: network.ID(c.NetworkPassphrase),
+	}
+
+	hash, err := tx.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &signatures{hash: hash, envelope: e, used: map[int]bool{}}
+	result := &Result{}
+
+	source := e.Tx.SourceAccount.Address()
+	result.Transaction, err = c.check(s, source, xdr.ThresholdIndexesThresholdLow, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range e.Tx.Operations {
+		opSource := source
+		if op.SourceAccount != nil {
+			opSource = op.SourceAccount.Address()
+		}
+
+		req, err := c.check(s, opSource, operationThreshold(op), &op.Body.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Operations = append(result.Operations, req)
+	}
+
+	for i := range e.Signatures {
+		if !s.used[i] {
+			result.UnusedSignatures = append(result.UnusedSignatures, i)
+		}
+	}
+
+	return result, nil
+}
+
+// check sums the weight of the signers of the source account that signed the
+// envelope.  When opType is nil every signer type is allowed.
+func (c *Checker) check(
+	s *signatures,
+	source string,
+	level xdr.ThresholdIndexes,
+	opType *xdr.OperationType,
+) (Requirement, error) {
+
+	account, ok := c.Accounts[source]
+	if !ok {
+		return Requirement{}, fmt.Errorf("Unknown source account %s", source)
+	}
+
+	ret := Requirement{
+		Source: source,
+		Level:  level,
+		Needed: int32(account.Thresholds[level]),
+	}
+
+	// the network always requires at least one signature
+	if ret.Needed == 0 {
+		ret.Needed = 1
+	}
+
+	for _, signer := range account.Signers {
+		if opType != nil && !allowedSigner(*opType, signer.Type) {
+			continue
+		}
+
+		if s.signedBy(signer.Address) {
+			ret.Weight += signer.Weight
+		}
+	}
+
+	return ret, nil
+}
+
+// allowedSigner returns true if a signer of type t may sign operations of
+// type op.
+func allowedSigner(op xdr.OperationType, t xdr.SignerType) bool {
+	switch t {
+	case xdr.SignerTypeSignerAdmin:
+		return op == xdr.OperationTypeAdministrative
+	case xdr.SignerTypeSignerEmission:
+		return op == xdr.OperationTypePayment
+	default:
+		return op != xdr.OperationTypeAdministrative
+	}
+}
+
+// operationThreshold returns the threshold the operation's source account
+// must meet.
+func operationThreshold(op xdr.Operation) xdr.ThresholdIndexes {
+	switch op.Body.Type {
+	case xdr.OperationTypeAllowTrust, xdr.OperationTypeInflation:
+		return xdr.ThresholdIndexesThresholdLow
+	case xdr.OperationTypeAccountMerge, xdr.OperationTypeAdministrative:
+		return xdr.ThresholdIndexesThresholdHigh
+	case xdr.OperationTypeSetOptions:
+		so := op.Body.MustSetOptionsOp()
+		if so.MasterWeight != nil || so.LowThreshold != nil ||
+			so.MedThreshold != nil || so.HighThreshold != nil ||
+			so.Signer != nil {
+			return xdr.ThresholdIndexesThresholdHigh
+		}
+	}
+
+	return xdr.ThresholdIndexesThresholdMed
+}
+
+// signatures tracks which of an envelope's signatures were verified for
+// which signer.
+type signatures struct {
+	hash     [32]byte
+	envelope *xdr.TransactionEnvelope
+	used     map[int]bool
+}
+
+// signedBy returns true if one of the envelope's signatures is a valid
+// signature by address, marking that signature as used.
+func (s *signatures) signedBy(address string) bool {
+	kp, err := keypair.Parse(address)
+	if err != nil {
+		return false
+	}
+
+	hint := kp.Hint()
+	for i, sig := range s.envelope.Signatures {
+		if sig.Hint != xdr.SignatureHint(hint) {
+			continue
+		}
+
+		if kp.Verify(s.hash[:], sig.Signature) == nil {
+			s.used[i] = true
+			return true
+		}
+	}
+
+	return false
+}
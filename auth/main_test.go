@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"testing"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/horizon"
+	"bitbucket.org/atticlab/go-smart-base/keypair"
+	"bitbucket.org/atticlab/go-smart-base/network"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAuth(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Package: bitbucket.org/atticlab/go-smart-base/auth")
+}
+
+var _ = Describe("auth.Checker", func() {
+	var (
+		source, cosigner, admin *keypair.Full
+		account                 Account
+		ops                     []build.TransactionMutator
+		seeds                   []string
+		result                  *Result
+		err                     error
+	)
+
+	random := func() *keypair.Full {
+		kp, err := keypair.Random()
+		Expect(err).NotTo(HaveOccurred())
+		return kp
+	}
+
+	BeforeEach(func() {
+		source, cosigner, admin = random(), random(), random()
+
+		account = Account{
+			Address:    source.Address(),
+			Thresholds: xdr.Thresholds{1, 1, 2, 3},
+			Signers: []Signer{
+				{Address: source.Address(), Weight: 1},
+				{Address: cosigner.Address(), Weight: 2},
+				{Address: admin.Address(), Weight: 3, Type: xdr.SignerTypeSignerAdmin},
+			},
+		}
+
+		ops = []build.TransactionMutator{build.Payment(
+			build.Destination{cosigner.Address()},
+			build.NativeAmount{"10"},
+		)}
+		seeds = []string{source.Seed()}
+	})
+
+	JustBeforeEach(func() {
+		b := build.TransactionEnvelopeBuilder{}
+		b.MutateTX(build.SourceAccount{source.Address()}, build.Sequence{1}, build.TestNetwork)
+		b.MutateTX(ops...)
+		for _, seed := range seeds {
+			b.Mutate(build.Sign{seed})
+		}
+		Expect(b.Err).NotTo(HaveOccurred())
+
+		checker := NewChecker(network.TestNetworkPassphrase, account)
+		result, err = checker.Check(b.E)
+	})
+
+	Context("with a payment signed by the master key only", func() {
+		It("meets the transaction threshold", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Transaction.Met()).To(BeTrue())
+		})
+
+		It("does not meet the medium threshold", func() {
+			Expect(result.Operations).To(HaveLen(1))
+			Expect(result.Operations[0].Level).To(Equal(xdr.ThresholdIndexesThresholdMed))
+			Expect(result.Operations[0].Weight).To(BeEquivalentTo(1))
+			Expect(result.Operations[0].Met()).To(BeFalse())
+			Expect(result.Authorized()).To(BeFalse())
+		})
+	})
+
+	Context("with a payment signed by the master key and the cosigner", func() {
+		BeforeEach(func() { seeds = append(seeds, cosigner.Seed()) })
+
+		It("is authorized", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Operations[0].Weight).To(BeEquivalentTo(3))
+			Expect(result.Authorized()).To(BeTrue())
+		})
+	})
+
+	Context("with a payment signed by an admin signer", func() {
+		BeforeEach(func() { seeds = append(seeds, admin.Seed()) })
+
+		It("does not count the admin signer", func() {
+			Expect(result.Operations[0].Weight).To(BeEquivalentTo(1))
+			Expect(result.Authorized()).To(BeFalse())
+		})
+	})
+
+	Context("with an administrative operation", func() {
+		BeforeEach(func() {
+			ops = []build.TransactionMutator{
+				build.AdministrativeOp(build.BlockAccount(cosigner.Address())),
+			}
+			seeds = []string{source.Seed(), admin.Seed()}
+		})
+
+		It("requires the high threshold from admin signers", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Operations[0].Level).To(Equal(xdr.ThresholdIndexesThresholdHigh))
+			Expect(result.Operations[0].Weight).To(BeEquivalentTo(3))
+			Expect(result.Authorized()).To(BeTrue())
+		})
+	})
+
+	Context("with a set options operation adding a signer", func() {
+		BeforeEach(func() {
+			ops = []build.TransactionMutator{
+				build.SetOptions(build.AddSigner(admin.Address(), 1, 0)),
+			}
+		})
+
+		It("requires the high threshold", func() {
+			Expect(result.Operations[0].Level).To(Equal(xdr.ThresholdIndexesThresholdHigh))
+		})
+	})
+
+	Context("with a signature from an unknown key", func() {
+		BeforeEach(func() { seeds = append(seeds, cosigner.Seed(), random().Seed()) })
+
+		It("reports the unused signature", func() {
+			Expect(result.UnusedSignatures).To(Equal([]int{2}))
+			Expect(result.Authorized()).To(BeFalse())
+		})
+	})
+
+	Context("with an unknown source account", func() {
+		BeforeEach(func() { account.Address = cosigner.Address() })
+
+		It("fails", func() { Expect(err).To(HaveOccurred()) })
+	})
+})
+
+var _ = Describe("auth.AccountFromEntry", func() {
+	It("includes the master key when it has a weight", func() {
+		var entry xdr.AccountEntry
+		entry.AccountId.SetAddress("GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ")
+		entry.Thresholds = xdr.Thresholds{2, 0, 0, 0}
+
+		account := AccountFromEntry(entry)
+		Expect(account.Signers).To(HaveLen(1))
+		Expect(account.Signers[0].Address).To(Equal(account.Address))
+		Expect(account.Signers[0].Weight).To(BeEquivalentTo(2))
+	})
+})
+
+var _ = Describe("auth.AccountFromHorizon", func() {
+	It("reads the thresholds and signers", func() {
+		var ha horizon.Account
+		ha.AccountID = "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+		ha.Thresholds = horizon.AccountThresholds{LowThreshold: 1, MedThreshold: 2, HighThreshold: 3}
+		ha.Signers = []horizon.Signer{
+			{PublicKey: ha.AccountID, Weight: 1},
+			{PublicKey: "GCNXDL2UN2UOZECXIO3SYDL4FSOLQXBKHKNO4EXKUNY2QBHKNF4K6VKQ", Weight: 2, SignerType: 1},
+		}
+
+		account := AccountFromHorizon(ha)
+		Expect(account.Thresholds).To(Equal(xdr.Thresholds{1, 1, 2, 3}))
+		Expect(account.Signers).To(HaveLen(2))
+		Expect(account.Signers[1].Type).To(Equal(xdr.SignerTypeSignerAdmin))
+	})
+})
@@ -0,0 +1,330 @@
+package build
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/commission"
+	"bitbucket.org/atticlab/go-smart-base/strkey"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// AdminCommand is a typed payload of an administrative operation.  Admin
+// commands are mutators of AdministrativeOpBuilder: applying one validates it
+// and sets the builder's OpData to the command's canonical JSON form:
+//
+//	{"type":"<command type>","data":{<command fields>}}
+type AdminCommand interface {
+	AdministrativeOpMutator
+	// CommandType returns the name identifying the command in its JSON form
+	CommandType() string
+	// Validate checks that all the required fields of the command are set
+	Validate() error
+}
+
+// adminCommands maps command types to decoders of the command's data and is
+// used when parsing commands back from administrative operations.
+var adminCommands = map[string]func([]byte) (AdminCommand, error){
+	"set_account_limits": func(data []byte) (AdminCommand, error) {
+		var cmd SetAccountLimits
+		err := decodeAdminCommandData(data, &cmd)
+		return cmd, err
+	},
+	"set_commission": func(data []byte) (AdminCommand, error) {
+		var cmd SetCommission
+		err := decodeAdminCommandData(data, &cmd)
+		return cmd, err
+	},
+	"set_traits": func(data []byte) (AdminCommand, error) {
+		var cmd SetTraits
+		err := decodeAdminCommandData(data, &cmd)
+		return cmd, err
+	},
+}
+
+type adminCommandEnvelope struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// SetAccountLimits is an admin command that sets the limits of the payments
+// the account can send and receive in the provided asset.  Limits left empty
+// are not restricted.
+type SetAccountLimits struct {
+	Account         string `json:"account"`
+	AssetCode       string `json:"asset_code"`
+	MaxOperationOut string `json:"max_operation_out,omitempty"`
+	DailyMaxOut     string `json:"daily_max_out,omitempty"`
+	MonthlyMaxOut   string `json:"monthly_max_out,omitempty"`
+	MaxOperationIn  string `json:"max_operation_in,omitempty"`
+	DailyMaxIn      string `json:"daily_max_in,omitempty"`
+	MonthlyMaxIn    string `json:"monthly_max_in,omitempty"`
+}
+
+// SetCommission is an admin command that sets a commission rule.  Key fields
+// left empty match any value; see the commission package for how rules are
+// resolved.
+type SetCommission struct {
+	From       string           `json:"from,omitempty"`
+	To         string           `json:"to,omitempty"`
+	FromType   *xdr.AccountType `json:"from_type,omitempty"`
+	ToType     *xdr.AccountType `json:"to_type,omitempty"`
+	Asset      *Asset           `json:"asset,omitempty"`
+	PercentFee string           `json:"percent_fee"`
+	FlatFee    string           `json:"flat_fee"`
+}
+
+// SetTraits is an admin command that blocks or unblocks incoming and outgoing
+// payments of an account.
+type SetTraits struct {
+	Account               string `json:"account"`
+	BlockIncomingPayments bool   `json:"block_incoming_payments"`
+	BlockOutgoingPayments bool   `json:"block_outgoing_payments"`
+}
+
+// BlockAccount returns an admin command blocking all payments of the account.
+func BlockAccount(address string) SetTraits {
+	return SetTraits{
+		Account:               address,
+		BlockIncomingPayments: true,
+		BlockOutgoingPayments: true,
+	}
+}
+
+// UnblockAccount returns an admin command unblocking all payments of the
+// account.
+func UnblockAccount(address string) SetTraits {
+	return SetTraits{Account: address}
+}
+
+// MarshalAdminCommand validates the command and returns its canonical JSON
+// form.
+func MarshalAdminCommand(cmd AdminCommand) (string, error) {
+	err := cmd.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	raw, err := json.Marshal(adminCommandEnvelope{
+		Type: cmd.CommandType(),
+		Data: data,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+// ParseAdminCommand parses and validates the admin command carried by the
+// provided administrative operation.
+func ParseAdminCommand(op xdr.AdministrativeOp) (AdminCommand, error) {
+	var envelope adminCommandEnvelope
+
+	err := json.Unmarshal([]byte(op.OpData), &envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	decode, ok := adminCommands[envelope.Type]
+	if !ok {
+		return nil, fmt.Errorf("Unknown admin command type: %q", envelope.Type)
+	}
+
+	cmd, err := decode(envelope.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cmd.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return cmd, nil
+}
+
+// CommandType implements AdminCommand
+func (m SetAccountLimits) CommandType() string {
+	return "set_account_limits"
+}
+
+// Validate implements AdminCommand
+func (m SetAccountLimits) Validate() error {
+	if err := validateAdminAccount(m.Account, true); err != nil {
+		return err
+	}
+
+	if len(m.AssetCode) < 1 || len(m.AssetCode) > 12 {
+		return errors.New("Asset code length is invalid")
+	}
+
+	limits := []string{
+		m.MaxOperationOut, m.DailyMaxOut, m.MonthlyMaxOut,
+		m.MaxOperationIn, m.DailyMaxIn, m.MonthlyMaxIn,
+	}
+
+	for _, limit := range limits {
+		if err := validateAdminAmount(limit, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MutateAdministrativeOp for SetAccountLimits sets the AdministrativeOpBuilder's
+// OpData field
+func (m SetAccountLimits) MutateAdministrativeOp(o *AdministrativeOpBuilder) error {
+	return setAdminCommand(o, m)
+}
+
+// CommandType implements AdminCommand
+func (m SetCommission) CommandType() string {
+	return "set_commission"
+}
+
+// Validate implements AdminCommand
+func (m SetCommission) Validate() error {
+	if err := validateAdminAccount(m.From, false); err != nil {
+		return err
+	}
+
+	if err := validateAdminAccount(m.To, false); err != nil {
+		return err
+	}
+
+	for _, t := range []*xdr.AccountType{m.FromType, m.ToType} {
+		if t != nil && !t.ValidEnum(int32(*t)) {
+			return fmt.Errorf("Invalid account type: %d", *t)
+		}
+	}
+
+	if m.Asset != nil {
+		if _, err := m.Asset.ToXdrObject(); err != nil {
+			return err
+		}
+	}
+
+	if err := validateAdminAmount(m.PercentFee, true); err != nil {
+		return err
+	}
+
+	return validateAdminAmount(m.FlatFee, true)
+}
+
+// Rule returns the commission rule set by the command.
+func (m SetCommission) Rule() (result commission.Rule, err error) {
+	err = m.Validate()
+	if err != nil {
+		return
+	}
+
+	if m.From != "" {
+		result.From = &xdr.AccountId{}
+		if err = setAccountId(m.From, result.From); err != nil {
+			return
+		}
+	}
+
+	if m.To != "" {
+		result.To = &xdr.AccountId{}
+		if err = setAccountId(m.To, result.To); err != nil {
+			return
+		}
+	}
+
+	if m.Asset != nil {
+		var asset xdr.Asset
+		if asset, err = m.Asset.ToXdrObject(); err != nil {
+			return
+		}
+		result.Asset = &asset
+	}
+
+	result.FromType = m.FromType
+	result.ToType = m.ToType
+	result.PercentFee = amount.MustParse(m.PercentFee)
+	result.FlatFee = amount.MustParse(m.FlatFee)
+	return
+}
+
+// MutateAdministrativeOp for SetCommission sets the AdministrativeOpBuilder's
+// OpData field
+func (m SetCommission) MutateAdministrativeOp(o *AdministrativeOpBuilder) error {
+	return setAdminCommand(o, m)
+}
+
+// CommandType implements AdminCommand
+func (m SetTraits) CommandType() string {
+	return "set_traits"
+}
+
+// Validate implements AdminCommand
+func (m SetTraits) Validate() error {
+	return validateAdminAccount(m.Account, true)
+}
+
+// MutateAdministrativeOp for SetTraits sets the AdministrativeOpBuilder's
+// OpData field
+func (m SetTraits) MutateAdministrativeOp(o *AdministrativeOpBuilder) error {
+	return setAdminCommand(o, m)
+}
+
+func setAdminCommand(o *AdministrativeOpBuilder, cmd AdminCommand) error {
+	data, err := MarshalAdminCommand(cmd)
+	if err != nil {
+		return err
+	}
+
+	o.OpData = xdr.LongString(data)
+	return nil
+}
+
+func decodeAdminCommandData(data []byte, dest interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(dest)
+}
+
+// validateAdminAccount ensures address is a valid account address.  Seeds are
+// rejected so that they can't end up in a payload submitted to the network.
+func validateAdminAccount(address string, required bool) error {
+	if address == "" {
+		if required {
+			return errors.New("Account is required")
+		}
+		return nil
+	}
+
+	_, err := strkey.Decode(strkey.VersionByteAccountID, address)
+	return err
+}
+
+func validateAdminAmount(v string, required bool) error {
+	if v == "" {
+		if required {
+			return errors.New("Amount is required")
+		}
+		return nil
+	}
+
+	parsed, err := amount.Parse(v)
+	if err != nil {
+		return err
+	}
+
+	if parsed < 0 {
+		return fmt.Errorf("Amount can't be negative: %s", v)
+	}
+
+	return nil
+}
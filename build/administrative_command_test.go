@@ -0,0 +1,178 @@
+package build
+
+import (
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AdminCommand Mutators", func() {
+
+	var (
+		subject AdministrativeOpBuilder
+		mut     interface{}
+
+		address = "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+		seed    = "SDOTALIMPAM2IV65IOZA7KZL7XWZI5BODFXTRVLIHLQZQCKK57PH5F3H"
+		bad     = "foo"
+	)
+
+	JustBeforeEach(func() {
+		subject = AdministrativeOpBuilder{}
+		subject.Mutate(mut)
+	})
+
+	parse := func() AdminCommand {
+		cmd, err := ParseAdminCommand(xdr.AdministrativeOp{OpData: subject.OpData})
+		Expect(err).NotTo(HaveOccurred())
+		return cmd
+	}
+
+	Describe("SetTraits", func() {
+		Context("blocking an account", func() {
+			BeforeEach(func() { mut = BlockAccount(address) })
+
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("sets the canonical payload", func() {
+				Expect(string(subject.OpData)).To(Equal(
+					`{"type":"set_traits","data":{"account":"` + address + `","block_incoming_payments":true,"block_outgoing_payments":true}}`,
+				))
+			})
+			It("round trips", func() { Expect(parse()).To(Equal(BlockAccount(address))) })
+		})
+
+		Context("unblocking an account", func() {
+			BeforeEach(func() { mut = UnblockAccount(address) })
+
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("round trips", func() { Expect(parse()).To(Equal(UnblockAccount(address))) })
+		})
+
+		Context("without an account", func() {
+			BeforeEach(func() { mut = SetTraits{BlockIncomingPayments: true} })
+			It("failed", func() { Expect(subject.Err).To(MatchError("Account is required")) })
+		})
+
+		Context("using a seed", func() {
+			BeforeEach(func() { mut = BlockAccount(seed) })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("SetAccountLimits", func() {
+		Context("with valid limits", func() {
+			BeforeEach(func() {
+				mut = SetAccountLimits{
+					Account:     address,
+					AssetCode:   "USD",
+					DailyMaxOut: "1000",
+					DailyMaxIn:  "5000.5",
+				}
+			})
+
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("omits unset limits", func() {
+				Expect(string(subject.OpData)).NotTo(ContainSubstring("monthly_max_out"))
+			})
+			It("round trips", func() {
+				Expect(parse()).To(Equal(SetAccountLimits{
+					Account:     address,
+					AssetCode:   "USD",
+					DailyMaxOut: "1000",
+					DailyMaxIn:  "5000.5",
+				}))
+			})
+		})
+
+		Context("without an asset code", func() {
+			BeforeEach(func() { mut = SetAccountLimits{Account: address} })
+			It("failed", func() { Expect(subject.Err).To(MatchError("Asset code length is invalid")) })
+		})
+
+		Context("with an invalid limit", func() {
+			BeforeEach(func() { mut = SetAccountLimits{Account: address, AssetCode: "USD", MaxOperationIn: "test"} })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+
+		Context("with a negative limit", func() {
+			BeforeEach(func() { mut = SetAccountLimits{Account: address, AssetCode: "USD", MaxOperationIn: "-1"} })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("SetCommission", func() {
+		merchant := xdr.AccountTypeAccountMerchant
+		asset := CreditAsset("USD", address)
+
+		Context("with a valid rule", func() {
+			BeforeEach(func() {
+				mut = SetCommission{
+					ToType:     &merchant,
+					Asset:      &asset,
+					PercentFee: "0.5",
+					FlatFee:    "1",
+				}
+			})
+
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("round trips", func() {
+				cmd := parse().(SetCommission)
+				Expect(*cmd.ToType).To(Equal(merchant))
+				Expect(*cmd.Asset).To(Equal(asset))
+				Expect(cmd.FromType).To(BeNil())
+				Expect(cmd.PercentFee).To(Equal("0.5"))
+			})
+			It("converts into a commission rule", func() {
+				rule, err := parse().(SetCommission).Rule()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(*rule.ToType).To(Equal(merchant))
+				Expect(rule.From).To(BeNil())
+				Expect(rule.Asset.Type).To(Equal(xdr.AssetTypeAssetTypeCreditAlphanum4))
+				Expect(rule.PercentFee).To(Equal(amount.MustParse("0.5")))
+				Expect(rule.FlatFee).To(Equal(amount.MustParse("1")))
+			})
+		})
+
+		Context("without fees", func() {
+			BeforeEach(func() { mut = SetCommission{From: address} })
+			It("failed", func() { Expect(subject.Err).To(MatchError("Amount is required")) })
+		})
+
+		Context("with an invalid account", func() {
+			BeforeEach(func() { mut = SetCommission{From: bad, PercentFee: "1", FlatFee: "0"} })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+
+		Context("with an invalid account type", func() {
+			BeforeEach(func() {
+				invalid := xdr.AccountType(8)
+				mut = SetCommission{FromType: &invalid, PercentFee: "1", FlatFee: "0"}
+			})
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("ParseAdminCommand", func() {
+		parseData := func(data string) error {
+			_, err := ParseAdminCommand(xdr.AdministrativeOp{OpData: xdr.LongString(data)})
+			return err
+		}
+
+		It("fails on malformed json", func() {
+			Expect(parseData("random_data")).To(HaveOccurred())
+		})
+
+		It("fails on unknown command types", func() {
+			Expect(parseData(`{"type":"unknown","data":{}}`)).To(MatchError(`Unknown admin command type: "unknown"`))
+		})
+
+		It("fails on unknown fields", func() {
+			Expect(parseData(`{"type":"set_traits","data":{"account":"` + address + `","foo":1}}`)).To(HaveOccurred())
+		})
+
+		It("fails on invalid commands", func() {
+			Expect(parseData(`{"type":"set_traits","data":{}}`)).To(MatchError("Account is required"))
+		})
+	})
+})
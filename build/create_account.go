@@ -2,7 +2,9 @@ package build
 
 import (
 	"errors"
+	"fmt"
 
+	"bitbucket.org/atticlab/go-smart-base/amount"
 	"bitbucket.org/atticlab/go-smart-base/xdr"
 )
 
@@ -53,15 +55,68 @@ func (m Destination) MutateCreateAccount(o *xdr.CreateAccountOp) error {
 	return setAccountId(m.AddressOrSeed, &o.Destination)
 }
 
-// MutateCreateAccount for AccountType sets the CreateAccountOp's
-// AccountType field
-// TODO: FIX IT
-// func (m CreateAccountWithScratch) MutateCreateAccount(o *xdr.CreateAccountOp) (err error) {
-// 	o.Body.AccountType = xdr.Uint32(m.AccountType)
-// 	switch m.AccountType {
-// 	case xdr.AccountTypeAccountScratchCard:
-// 		o.Body.ScratchCard.Asset = m.Asset
-// 		o.Body.ScratchCard.Amount = m.Amount
-// 	}
-// 	return
-// }
+// MutateCreateAccount for AccountType sets the CreateAccountOp's Body to
+// the provided account type
+func (m AccountType) MutateCreateAccount(o *xdr.CreateAccountOp) (err error) {
+	accountType, err := validAccountType(uint32(m))
+	if err != nil {
+		return
+	}
+
+	if accountType == xdr.AccountTypeAccountScratchCard {
+		return errors.New("Scratch card accounts require asset and amount")
+	}
+
+	o.Body, err = xdr.NewCreateAccountOpBody(accountType, nil)
+	return
+}
+
+// MutateCreateAccount for CreateAccountWithScratch sets the CreateAccountOp's
+// Body to the provided account type, including the scratch card's asset and
+// amount when creating a scratch card account
+func (m CreateAccountWithScratch) MutateCreateAccount(o *xdr.CreateAccountOp) (err error) {
+	accountType, err := validAccountType(m.AccountType)
+	if err != nil {
+		return
+	}
+
+	if accountType != xdr.AccountTypeAccountScratchCard {
+		if m.Asset != nil || m.Amount != "" {
+			return errors.New("Asset and amount can only be set for scratch card accounts")
+		}
+
+		o.Body, err = xdr.NewCreateAccountOpBody(accountType, nil)
+		return
+	}
+
+	if m.Asset == nil || m.Amount == "" {
+		return errors.New("Scratch card accounts require asset and amount")
+	}
+
+	var card xdr.ScratchCard
+	card.Asset, err = m.Asset.ToXdrObject()
+	if err != nil {
+		return
+	}
+
+	card.Amount, err = amount.Parse(m.Amount)
+	if err != nil {
+		return
+	}
+
+	if card.Amount <= 0 {
+		return errors.New("Scratch card amount must be positive")
+	}
+
+	o.Body, err = xdr.NewCreateAccountOpBody(accountType, card)
+	return
+}
+
+func validAccountType(t uint32) (xdr.AccountType, error) {
+	accountType := xdr.AccountType(t)
+	if !accountType.ValidEnum(int32(t)) {
+		return 0, fmt.Errorf("Invalid account type: %d", t)
+	}
+
+	return accountType, nil
+}
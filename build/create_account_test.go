@@ -4,6 +4,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"bitbucket.org/atticlab/go-smart-base"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
 )
 
 var _ = Describe("CreateAccountBuilder Mutators", func() {
@@ -60,4 +61,131 @@ var _ = Describe("CreateAccountBuilder Mutators", func() {
 			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
 		})
 	})
+
+	Describe("AccountType", func() {
+		Context("using a valid account type", func() {
+			BeforeEach(func() { mut = AccountType(xdr.AccountTypeAccountMerchant) })
+
+			It("succeeds", func() {
+				Expect(subject.Err).NotTo(HaveOccurred())
+			})
+
+			It("sets the account type", func() {
+				Expect(subject.CA.Body.AccountType).To(Equal(xdr.AccountTypeAccountMerchant))
+				Expect(subject.CA.Body.ScratchCard).To(BeNil())
+			})
+		})
+
+		Context("using the scratch card account type", func() {
+			BeforeEach(func() { mut = AccountType(xdr.AccountTypeAccountScratchCard) })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+
+		Context("using an unknown account type", func() {
+			BeforeEach(func() { mut = AccountType(8) })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("CreateAccountWithScratch", func() {
+		asset := CreditAsset("USD", address)
+
+		Context("creating a scratch card", func() {
+			BeforeEach(func() {
+				mut = CreateAccountWithScratch{
+					AccountType: uint32(xdr.AccountTypeAccountScratchCard),
+					Asset:       &asset,
+					Amount:      "25",
+				}
+			})
+
+			It("succeeds", func() {
+				Expect(subject.Err).NotTo(HaveOccurred())
+			})
+
+			It("sets the scratch card body", func() {
+				Expect(subject.CA.Body.AccountType).To(Equal(xdr.AccountTypeAccountScratchCard))
+				card := subject.CA.Body.MustScratchCard()
+				Expect(card.Amount).To(Equal(xdr.Int64(250000000)))
+				Expect(card.Asset.Type).To(Equal(xdr.AssetTypeAssetTypeCreditAlphanum4))
+				Expect(card.Asset.AlphaNum4.AssetCode).To(Equal([4]byte{'U', 'S', 'D', 0}))
+			})
+		})
+
+		Context("creating a scratch card without an amount", func() {
+			BeforeEach(func() {
+				mut = CreateAccountWithScratch{
+					AccountType: uint32(xdr.AccountTypeAccountScratchCard),
+					Asset:       &asset,
+				}
+			})
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+
+		Context("creating a scratch card with a non-positive amount", func() {
+			BeforeEach(func() {
+				mut = CreateAccountWithScratch{
+					AccountType: uint32(xdr.AccountTypeAccountScratchCard),
+					Asset:       &asset,
+					Amount:      "0",
+				}
+			})
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+
+		Context("creating a regular account", func() {
+			BeforeEach(func() {
+				mut = CreateAccountWithScratch{
+					AccountType: uint32(xdr.AccountTypeAccountRegisteredUser),
+				}
+			})
+
+			It("succeeds", func() {
+				Expect(subject.Err).NotTo(HaveOccurred())
+			})
+
+			It("sets the account type", func() {
+				Expect(subject.CA.Body.AccountType).To(Equal(xdr.AccountTypeAccountRegisteredUser))
+				Expect(subject.CA.Body.ScratchCard).To(BeNil())
+			})
+		})
+
+		Context("creating a regular account with an asset", func() {
+			BeforeEach(func() {
+				mut = CreateAccountWithScratch{
+					AccountType: uint32(xdr.AccountTypeAccountRegisteredUser),
+					Asset:       &asset,
+					Amount:      "25",
+				}
+			})
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("tx", func() {
+		It("encodes a scratch card account creation", func() {
+			asset := CreditAsset("USD", address)
+			tx := Transaction(
+				SourceAccount{address},
+				Sequence{1},
+				CreateAccount(
+					Destination{"GAWSI2JO2CF36Z43UGMUJCDQ2IMR5B3P5TMS7XM7NUTU3JHG3YJUDQXA"},
+					CreateAccountWithScratch{
+						AccountType: uint32(xdr.AccountTypeAccountScratchCard),
+						Asset:       &asset,
+						Amount:      "25",
+					},
+				),
+			)
+			Expect(tx.Err).NotTo(HaveOccurred())
+
+			raw, err := xdr.MarshalBase64(tx.TX)
+			Expect(err).NotTo(HaveOccurred())
+
+			var decoded xdr.Transaction
+			Expect(xdr.SafeUnmarshalBase64(raw, &decoded)).To(Succeed())
+			card := decoded.Operations[0].Body.MustCreateAccountOp().Body.MustScratchCard()
+			Expect(card.Amount).To(Equal(xdr.Int64(250000000)))
+		})
+	})
 })
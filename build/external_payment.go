@@ -0,0 +1,112 @@
+package build
+
+import (
+	"errors"
+
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// ExternalPayment groups the creation of a new ExternalPaymentBuilder with a
+// call to Mutate.
+func ExternalPayment(muts ...interface{}) (result ExternalPaymentBuilder) {
+	result.Mutate(muts...)
+	return
+}
+
+// ExternalPaymentMutator is a interface that wraps the
+// MutateExternalPayment operation.  types may implement this interface to
+// specify how they modify an xdr.ExternalPaymentOp object
+type ExternalPaymentMutator interface {
+	MutateExternalPayment(*xdr.ExternalPaymentOp) error
+}
+
+// ExternalPaymentBuilder helps to build ExternalPaymentOp structs.
+type ExternalPaymentBuilder struct {
+	O   xdr.Operation
+	EP  xdr.ExternalPaymentOp
+	Err error
+}
+
+// Mutate applies the provided mutators to this builder's external payment or
+// operation.
+func (b *ExternalPaymentBuilder) Mutate(muts ...interface{}) {
+	for _, m := range muts {
+		var err error
+		switch mut := m.(type) {
+		case ExternalPaymentMutator:
+			err = mut.MutateExternalPayment(&b.EP)
+		case OperationMutator:
+			err = mut.MutateOperation(&b.O)
+		default:
+			err = errors.New("Mutator type not allowed")
+		}
+
+		if err != nil {
+			b.Err = err
+			return
+		}
+	}
+
+	if err := b.validate(); err != nil {
+		b.Err = err
+	}
+}
+
+// validate ensures that the exchange agent, destination bank and destination
+// account, if they have been set, are distinct accounts.
+func (b *ExternalPaymentBuilder) validate() error {
+	ids := []xdr.AccountId{
+		b.EP.ExchangeAgent,
+		b.EP.DestinationBank,
+		b.EP.DestinationAccount,
+	}
+
+	for i := range ids {
+		if ids[i] == (xdr.AccountId{}) {
+			continue
+		}
+
+		for j := i + 1; j < len(ids); j++ {
+			if ids[j] == (xdr.AccountId{}) {
+				continue
+			}
+
+			if ids[i].Equals(ids[j]) {
+				return errors.New("Exchange agent, destination bank and destination account must be distinct")
+			}
+		}
+	}
+
+	return nil
+}
+
+// MutateExternalPayment for CreditAmount sets the ExternalPaymentOp's Asset
+// and Amount fields
+func (m CreditAmount) MutateExternalPayment(o *xdr.ExternalPaymentOp) (err error) {
+	o.Amount, err = amount.Parse(m.Amount)
+	if err != nil {
+		return
+	}
+
+	o.Asset, err = createAlphaNumAsset(m.Code, m.Issuer)
+	return
+}
+
+// MutateExternalPayment for DestinationAccount sets the ExternalPaymentOp's
+// DestinationAccount field
+func (m DestinationAccount) MutateExternalPayment(o *xdr.ExternalPaymentOp) error {
+	return setAccountId(m.AddressOrSeed, &o.DestinationAccount)
+}
+
+// MutateExternalPayment for DestinationBank sets the ExternalPaymentOp's
+// DestinationBank field
+func (m DestinationBank) MutateExternalPayment(o *xdr.ExternalPaymentOp) error {
+	return setAccountId(m.AddressOrSeed, &o.DestinationBank)
+}
+
+// MutateExternalPayment for ExchangeAgent sets the ExternalPaymentOp's
+// ExchangeAgent field
+func (m ExchangeAgent) MutateExternalPayment(o *xdr.ExternalPaymentOp) error {
+	return setAccountId(m.AddressOrSeed, &o.ExchangeAgent)
+}
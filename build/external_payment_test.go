@@ -0,0 +1,195 @@
+package build
+
+import (
+	"bitbucket.org/atticlab/go-smart-base"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExternalPaymentBuilder Mutators", func() {
+
+	var (
+		subject ExternalPaymentBuilder
+		mut     interface{}
+
+		address = "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+		bank    = "GAWSI2JO2CF36Z43UGMUJCDQ2IMR5B3P5TMS7XM7NUTU3JHG3YJUDQXA"
+		agent   = "GCPZJ3MJQ3GUGJSBL6R3MLYZS6FKVHG67BPAINMXL3NWNXR5S6XG657P"
+		bad     = "foo"
+	)
+
+	JustBeforeEach(func() {
+		subject = ExternalPaymentBuilder{}
+		subject.Mutate(mut)
+	})
+
+	Describe("CreditAmount", func() {
+		Context("AlphaNum4", func() {
+			BeforeEach(func() {
+				mut = CreditAmount{"USD", address, "50.0"}
+			})
+			It("sets the asset properly", func() {
+				Expect(subject.EP.Amount).To(Equal(xdr.Int64(500000000)))
+				Expect(subject.EP.Asset.Type).To(Equal(xdr.AssetTypeAssetTypeCreditAlphanum4))
+				Expect(subject.EP.Asset.AlphaNum4.AssetCode).To(Equal([4]byte{'U', 'S', 'D', 0}))
+				aid, _ := stellarbase.AddressToAccountId(address)
+				Expect(subject.EP.Asset.AlphaNum4.Issuer.MustEd25519()).To(Equal(aid.MustEd25519()))
+				Expect(subject.EP.Asset.AlphaNum12).To(BeNil())
+			})
+			It("succeeds", func() {
+				Expect(subject.Err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("issuer invalid", func() {
+			BeforeEach(func() {
+				mut = CreditAmount{"USD", bad, "50.0"}
+			})
+
+			It("failed", func() {
+				Expect(subject.Err).To(HaveOccurred())
+			})
+		})
+
+		Context("amount invalid", func() {
+			BeforeEach(func() {
+				mut = CreditAmount{"USD", address, "test"}
+			})
+
+			It("failed", func() {
+				Expect(subject.Err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("ExchangeAgent", func() {
+		Context("using a valid stellar address", func() {
+			BeforeEach(func() { mut = ExchangeAgent{address} })
+
+			It("succeeds", func() {
+				Expect(subject.Err).NotTo(HaveOccurred())
+			})
+
+			It("sets the exchange agent to the correct xdr.AccountId", func() {
+				aid, _ := stellarbase.AddressToAccountId(address)
+				Expect(subject.EP.ExchangeAgent.MustEd25519()).To(Equal(aid.MustEd25519()))
+			})
+		})
+
+		Context("using an invalid value", func() {
+			BeforeEach(func() { mut = ExchangeAgent{bad} })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("DestinationBank", func() {
+		Context("using a valid stellar address", func() {
+			BeforeEach(func() { mut = DestinationBank{address} })
+
+			It("succeeds", func() {
+				Expect(subject.Err).NotTo(HaveOccurred())
+			})
+
+			It("sets the destination bank to the correct xdr.AccountId", func() {
+				aid, _ := stellarbase.AddressToAccountId(address)
+				Expect(subject.EP.DestinationBank.MustEd25519()).To(Equal(aid.MustEd25519()))
+			})
+		})
+
+		Context("using an invalid value", func() {
+			BeforeEach(func() { mut = DestinationBank{bad} })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("DestinationAccount", func() {
+		Context("using a valid stellar address", func() {
+			BeforeEach(func() { mut = DestinationAccount{address} })
+
+			It("succeeds", func() {
+				Expect(subject.Err).NotTo(HaveOccurred())
+			})
+
+			It("sets the destination account to the correct xdr.AccountId", func() {
+				aid, _ := stellarbase.AddressToAccountId(address)
+				Expect(subject.EP.DestinationAccount.MustEd25519()).To(Equal(aid.MustEd25519()))
+			})
+		})
+
+		Context("using an invalid value", func() {
+			BeforeEach(func() { mut = DestinationAccount{bad} })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("SourceAccount", func() {
+		Context("using a valid stellar address", func() {
+			BeforeEach(func() { mut = SourceAccount{address} })
+
+			It("succeeds", func() {
+				Expect(subject.Err).NotTo(HaveOccurred())
+			})
+
+			It("sets the source account to the correct xdr.AccountId", func() {
+				aid, _ := stellarbase.AddressToAccountId(address)
+				Expect(subject.O.SourceAccount.MustEd25519()).To(Equal(aid.MustEd25519()))
+			})
+		})
+
+		Context("using an invalid value", func() {
+			BeforeEach(func() { mut = SourceAccount{bad} })
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("account validation", func() {
+		Context("with distinct accounts", func() {
+			It("succeeds", func() {
+				b := ExternalPayment(
+					ExchangeAgent{agent},
+					DestinationBank{bank},
+					DestinationAccount{address},
+				)
+				Expect(b.Err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("with the same bank and destination account", func() {
+			It("failed", func() {
+				b := ExternalPayment(
+					ExchangeAgent{agent},
+					DestinationBank{address},
+					DestinationAccount{address},
+				)
+				Expect(b.Err).To(HaveOccurred())
+			})
+		})
+
+		Context("with the same exchange agent and destination bank", func() {
+			It("failed", func() {
+				b := ExternalPayment(ExchangeAgent{bank}, DestinationBank{bank})
+				Expect(b.Err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("tx", func() {
+		It("adds an external payment operation", func() {
+			tx := Transaction(
+				SourceAccount{agent},
+				Sequence{1},
+				ExternalPayment(
+					ExchangeAgent{agent},
+					DestinationBank{bank},
+					DestinationAccount{address},
+					CreditAmount{"USD", bank, "50"},
+				),
+			)
+			Expect(tx.Err).NotTo(HaveOccurred())
+			Expect(tx.TX.Operations).To(HaveLen(1))
+			Expect(tx.TX.Operations[0].Body.Type).To(Equal(xdr.OperationTypeExternalPayment))
+			Expect(tx.TX.Operations[0].Body.MustExternalPaymentOp().Amount).To(Equal(xdr.Int64(500000000)))
+		})
+	})
+})
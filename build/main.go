@@ -10,6 +10,7 @@ package build
 import (
 	"errors"
 	"math"
+	"time"
 
 	"bitbucket.org/atticlab/go-smart-base/amount"
 	"bitbucket.org/atticlab/go-smart-base/network"
@@ -31,6 +32,11 @@ var (
 	// to the test stellar network (often called testnet).
 	TestNetwork = Network{network.TestNetworkPassphrase}
 
+	// DefaultBaseFee is the fee paid per operation of a transaction when no
+	// `BaseFee` or `Fee` mutator is used.  Integrators may change this value if
+	// they would like to effect the default in a process-global manner.
+	DefaultBaseFee uint32 = 0
+
 	// DefaultNetwork is a mutator that configures the transaction for submission
 	// to the default stellar network.  Integrators may change this value to
 	// another `Network` mutator if they would like to effect the default in a
@@ -43,9 +49,9 @@ type Amount string
 
 // Asset is struct used in path_payment mutators
 type Asset struct {
-	Code   string
-	Issuer string
-	Native bool
+	Code   string `json:"code,omitempty"`
+	Issuer string `json:"issuer,omitempty"`
+	Native bool   `json:"native,omitempty"`
 }
 
 // ToXdrObject creates xdr.Asset object from build.Asset object
@@ -126,15 +132,69 @@ type PaymentSender struct {
 	AddressOrSeed string
 }
 
+// BaseFee is a mutator that sets the fee paid per operation of a
+// transaction.  The transaction's fee is calculated once all operations have
+// been added, unless an explicit Fee is set.
+type BaseFee uint32
+
+// BaseFeeProvider is the interface that other packages may implement to be
+// used with the `AutoBaseFee` mutator.
+type BaseFeeProvider interface {
+	BaseFee() (uint32, error)
+}
+
+// AutoBaseFee loads the base fee to use for the transaction from an external
+// provider.
+type AutoBaseFee struct {
+	BaseFeeProvider
+}
+
 // Defaults is a mutator that sets defaults
 type Defaults struct{}
 
+// Fee is a mutator that sets the total fee of a transaction, overriding any
+// base fee.
+type Fee uint32
+
 // Destination is a mutator capable of setting the destination on
 // an operations that have one.
 type Destination struct {
 	AddressOrSeed string
 }
 
+// DestinationAccount is a mutator capable of setting the recipient account
+// of an external payment.
+type DestinationAccount struct {
+	AddressOrSeed string
+}
+
+// DestinationBank is a mutator capable of setting the recipient bank of an
+// external payment.
+type DestinationBank struct {
+	AddressOrSeed string
+}
+
+// ExchangeAgent is a mutator capable of setting the exchange agent of an
+// external payment.
+type ExchangeAgent struct {
+	AddressOrSeed string
+}
+
+// NoOperationFee is a mutator that records on a transaction envelope that no
+// fee is charged for the next operation of the transaction.
+type NoOperationFee struct{}
+
+// OperationFee is a mutator that records on a transaction envelope the fee
+// charged for the next operation of the transaction.  Amount is the total
+// amount to charge; PercentFee and FlatFee optionally describe how it was
+// derived and may be left empty.
+type OperationFee struct {
+	Asset      Asset
+	Amount     string
+	PercentFee string
+	FlatFee    string
+}
+
 // OpLongData is a mutator capable of setting the OpData on
 // an operations that have one.
 type OpLongData struct {
@@ -159,12 +219,19 @@ type Limit Amount
 // MasterWeight is a mutator that sets account's master weight
 type MasterWeight uint32
 
+// AccountType is a mutator that sets the type of the account being created
+// by a create_account operation.  Scratch card accounts must be created using
+// the CreateAccountWithScratch mutator instead.
 type AccountType uint32
 
+// CreateAccountWithScratch is a mutator that sets the type of the account
+// being created by a create_account operation.  Asset and Amount must be
+// provided when AccountType is xdr.AccountTypeAccountScratchCard and must be
+// omitted otherwise.
 type CreateAccountWithScratch struct {
 	AccountType uint32
-	Asset  *Asset
-	Amount *uint64
+	Asset       *Asset
+	Amount      string
 }
 
 // MaxLimit represents the maximum value that can be passed as trutline Limit
@@ -271,6 +338,18 @@ type Thresholds struct {
 	High   *uint32
 }
 
+// TimeBounds is a mutator that sets the time bounds, expressed in unix
+// seconds, during which a transaction is valid.  A MaxTime of zero means the
+// transaction never expires.
+type TimeBounds struct {
+	MinTime uint64
+	MaxTime uint64
+}
+
+// Timeout is a mutator that makes a transaction expire once the provided
+// duration has elapsed from the moment the mutator is applied.
+type Timeout time.Duration
+
 // Trustor is a mutator capable of setting the trustor on
 // allow_trust operation.
 type Trustor struct {
@@ -0,0 +1,174 @@
+package build
+
+import (
+	"errors"
+
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/commission"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// MaxOperationFees is the maximum number of operation fees a transaction
+// envelope may carry.
+const MaxOperationFees = 100
+
+// ComputeOperationFee calculates the fee charged for an operation moving
+// paymentAmount of asset, given a commission made of a percent part and a
+// flat part.  The percent part is expressed in percents (i.e. "0.5" is half a
+// percent) and is rounded down to the nearest stroop, as done by
+// commission.Calculate.  Either part may be left empty.  The returned
+// OperationFee can be applied to an envelope directly, allowing the fee to be
+// shown to the user before signing.
+func ComputeOperationFee(asset Asset, paymentAmount, percentFee, flatFee string) (result OperationFee, err error) {
+	raw, err := amount.Parse(paymentAmount)
+	if err != nil {
+		return
+	}
+
+	percent, err := parseOptionalAmount(percentFee)
+	if err != nil {
+		return
+	}
+
+	flat, err := parseOptionalAmount(flatFee)
+	if err != nil {
+		return
+	}
+
+	fee, err := operationFeeAmount(raw, percent, flat)
+	if err != nil {
+		return
+	}
+
+	result = OperationFee{
+		Asset:      asset,
+		Amount:     amount.String(fee),
+		PercentFee: percentFee,
+		FlatFee:    flatFee,
+	}
+	return
+}
+
+// CommissionOperationFee calculates the fee charged by a commission rule for
+// an operation moving paymentAmount of asset.  See ComputeOperationFee.
+func CommissionOperationFee(asset Asset, paymentAmount string, rule commission.Rule) (OperationFee, error) {
+	return ComputeOperationFee(
+		asset,
+		paymentAmount,
+		amount.String(rule.PercentFee),
+		amount.String(rule.FlatFee),
+	)
+}
+
+// operationFeeAmount returns the commission charged on paymentAmount,
+// treating unset fee parts as zero.
+func operationFeeAmount(paymentAmount xdr.Int64, percent, flat *xdr.Int64) (xdr.Int64, error) {
+	var percentFee, flatFee xdr.Int64
+
+	if percent != nil {
+		percentFee = *percent
+	}
+
+	if flat != nil {
+		flatFee = *flat
+	}
+
+	return commission.Calculate(paymentAmount, percentFee, flatFee)
+}
+
+func parseOptionalAmount(v string) (*xdr.Int64, error) {
+	if v == "" {
+		return nil, nil
+	}
+
+	parsed, err := amount.Parse(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+// appendOperationFee adds fee to the envelope, ensuring that no more than one
+// fee is recorded per operation.
+func appendOperationFee(txe *TransactionEnvelopeBuilder, fee xdr.OperationFee) error {
+	if len(txe.E.OperationFees) >= MaxOperationFees {
+		return errors.New("Too many operation fees")
+	}
+
+	if len(txe.E.OperationFees) >= len(txe.E.Tx.Operations) {
+		return errors.New("Operation fee has no matching operation")
+	}
+
+	txe.E.OperationFees = append(txe.E.OperationFees, fee)
+	return nil
+}
+
+// validateOperationFees ensures the envelope does not carry more operation
+// fees than its transaction has operations.
+func validateOperationFees(e *xdr.TransactionEnvelope) error {
+	if len(e.OperationFees) > MaxOperationFees {
+		return errors.New("Too many operation fees")
+	}
+
+	if len(e.OperationFees) > len(e.Tx.Operations) {
+		return errors.New("Operation fee has no matching operation")
+	}
+
+	return nil
+}
+
+// ------------------------------------------------------------
+//
+//   Mutator implementations
+//
+// ------------------------------------------------------------
+
+// MutateTransactionEnvelope for NoOperationFee records that no fee is
+// charged for the next operation
+func (m NoOperationFee) MutateTransactionEnvelope(txe *TransactionEnvelopeBuilder) error {
+	fee, err := xdr.NewOperationFee(xdr.OperationFeeTypeOpFeeNone, nil)
+	if err != nil {
+		return err
+	}
+
+	return appendOperationFee(txe, fee)
+}
+
+// MutateTransactionEnvelope for OperationFee records the fee charged for the
+// next operation
+func (m OperationFee) MutateTransactionEnvelope(txe *TransactionEnvelopeBuilder) error {
+	var charged xdr.OperationFeeFee
+	var err error
+
+	charged.Asset, err = m.Asset.ToXdrObject()
+	if err != nil {
+		return err
+	}
+
+	charged.AmountToCharge, err = amount.Parse(m.Amount)
+	if err != nil {
+		return err
+	}
+
+	if charged.AmountToCharge < 0 {
+		return errors.New("Amount to charge can't be negative")
+	}
+
+	charged.PercentFee, err = parseOptionalAmount(m.PercentFee)
+	if err != nil {
+		return err
+	}
+
+	charged.FlatFee, err = parseOptionalAmount(m.FlatFee)
+	if err != nil {
+		return err
+	}
+
+	fee, err := xdr.NewOperationFee(xdr.OperationFeeTypeOpFeeCharged, charged)
+	if err != nil {
+		return err
+	}
+
+	return appendOperationFee(txe, fee)
+}
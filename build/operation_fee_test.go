@@ -0,0 +1,168 @@
+package build
+
+import (
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/commission"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OperationFee Mutators", func() {
+
+	var (
+		subject TransactionEnvelopeBuilder
+		mut     TransactionEnvelopeMutator
+
+		seed    = "SDOTALIMPAM2IV65IOZA7KZL7XWZI5BODFXTRVLIHLQZQCKK57PH5F3H"
+		address = "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+	)
+
+	BeforeEach(func() {
+		subject = TransactionEnvelopeBuilder{}
+		subject.Mutate(Transaction(
+			SourceAccount{seed},
+			Sequence{1},
+			Payment(Destination{address}, CreditAmount{"USD", address, "100"}),
+		))
+	})
+	JustBeforeEach(func() { subject.Mutate(mut) })
+
+	Describe("NoOperationFee", func() {
+		BeforeEach(func() { mut = NoOperationFee{} })
+
+		It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+		It("adds an empty operation fee", func() {
+			Expect(subject.E.OperationFees).To(HaveLen(1))
+			Expect(subject.E.OperationFees[0].Type).To(Equal(xdr.OperationFeeTypeOpFeeNone))
+		})
+	})
+
+	Describe("OperationFee", func() {
+		Context("with percent and flat parts", func() {
+			BeforeEach(func() {
+				mut = OperationFee{
+					Asset:      CreditAsset("USD", address),
+					Amount:     "1.5",
+					PercentFee: "1",
+					FlatFee:    "0.5",
+				}
+			})
+
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("adds a charged operation fee", func() {
+				Expect(subject.E.OperationFees).To(HaveLen(1))
+				fee := subject.E.OperationFees[0].MustFee()
+				Expect(fee.AmountToCharge).To(Equal(xdr.Int64(15000000)))
+				Expect(*fee.PercentFee).To(Equal(xdr.Int64(10000000)))
+				Expect(*fee.FlatFee).To(Equal(xdr.Int64(5000000)))
+				Expect(fee.Asset.Type).To(Equal(xdr.AssetTypeAssetTypeCreditAlphanum4))
+			})
+		})
+
+		Context("without percent and flat parts", func() {
+			BeforeEach(func() {
+				mut = OperationFee{Asset: CreditAsset("USD", address), Amount: "1"}
+			})
+
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("leaves the optional parts unset", func() {
+				fee := subject.E.OperationFees[0].MustFee()
+				Expect(fee.PercentFee).To(BeNil())
+				Expect(fee.FlatFee).To(BeNil())
+			})
+		})
+
+		Context("with an invalid amount", func() {
+			BeforeEach(func() {
+				mut = OperationFee{Asset: CreditAsset("USD", address), Amount: "test"}
+			})
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+
+		Context("with a negative amount", func() {
+			BeforeEach(func() {
+				mut = OperationFee{Asset: CreditAsset("USD", address), Amount: "-1"}
+			})
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("more fees than operations", func() {
+		BeforeEach(func() {
+			subject.Mutate(NoOperationFee{})
+			mut = NoOperationFee{}
+		})
+
+		It("failed", func() {
+			Expect(subject.Err).To(MatchError("Operation fee has no matching operation"))
+			Expect(subject.E.OperationFees).To(HaveLen(1))
+		})
+	})
+
+	Describe("Sign", func() {
+		Context("with an operation fee for each operation", func() {
+			BeforeEach(func() {
+				subject.Mutate(NoOperationFee{})
+				mut = Sign{seed}
+			})
+
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("keeps the operation fees", func() {
+				Expect(subject.E.Signatures).To(HaveLen(1))
+				Expect(subject.E.OperationFees).To(HaveLen(1))
+			})
+		})
+
+		Context("with more operation fees than operations", func() {
+			BeforeEach(func() {
+				subject.E.OperationFees = make([]xdr.OperationFee, 2)
+				mut = Sign{seed}
+			})
+
+			It("failed", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("ComputeOperationFee", func() {
+		asset := CreditAsset("USD", address)
+
+		It("combines the percent and flat parts", func() {
+			fee, err := ComputeOperationFee(asset, "200", "1.5", "0.25")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fee.Amount).To(Equal("3.2500000"))
+			Expect(fee.PercentFee).To(Equal("1.5"))
+			Expect(fee.FlatFee).To(Equal("0.25"))
+			Expect(fee.Asset).To(Equal(asset))
+		})
+
+		It("rounds the percent part down", func() {
+			fee, err := ComputeOperationFee(asset, "0.0000099", "10", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fee.Amount).To(Equal("0.0000009"))
+		})
+
+		It("allows a zero fee", func() {
+			fee, err := ComputeOperationFee(asset, "100", "", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fee.Amount).To(Equal("0.0000000"))
+		})
+
+		It("uses the fee parts of a commission rule", func() {
+			rule := commission.Rule{PercentFee: amount.MustParse("1"), FlatFee: amount.MustParse("0.5")}
+			fee, err := CommissionOperationFee(asset, "100", rule)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fee.Amount).To(Equal("1.5000000"))
+		})
+
+		It("fails on an invalid amount", func() {
+			_, err := ComputeOperationFee(asset, "test", "1", "")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fails on a negative percent", func() {
+			_, err := ComputeOperationFee(asset, "100", "-1", "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
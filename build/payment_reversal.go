@@ -101,3 +101,178 @@ func (m PaymentSender) MutatePaymentReversal(o interface{}) error {
 	}
 	return nil
 }
+
+// Errors returned when a payment reversal would be rejected by the network
+// because it does not match the payment it reverses.  Each error corresponds
+// to the xdr.PaymentReversalResultCode of the same name.
+var (
+	ErrReversalInvalidAmount        = errors.New("payment reversal: amount does not match the payment")
+	ErrReversalInvalidCommission    = errors.New("payment reversal: commission does not match the payment")
+	ErrReversalInvalidPaymentSender = errors.New("payment reversal: payment sender does not match the payment source")
+	ErrReversalInvalidAsset         = errors.New("payment reversal: asset does not match the payment")
+	ErrReversalInvalidSource        = errors.New("payment reversal: source does not match the payment destination")
+)
+
+// PaymentRecord describes a payment applied by the network.
+type PaymentRecord struct {
+	// ID is the id of the payment operation, as reported by horizon
+	ID int64
+	// Envelope is the envelope of the transaction containing the payment
+	Envelope xdr.TransactionEnvelope
+	// Result is the result of the transaction containing the payment
+	Result xdr.TransactionResult
+	// Meta is the meta of the transaction containing the payment, used to
+	// confirm that the payment credited its destination
+	Meta xdr.TransactionMeta
+	// OpIndex is the index of the payment within the transaction
+	OpIndex int
+}
+
+// PaymentReversalFor creates a PaymentReversalBuilder reversing the payment
+// described by `record`, with the payment sender, asset, amount, commission,
+// id and source account populated from the original payment.  Additional
+// mutators are applied afterwards; the resulting operation is then checked
+// against the payment so that mismatches surface locally instead of as a
+// PAYMENT_REVERSAL_INVALID_* result code.
+func PaymentReversalFor(record PaymentRecord, muts ...interface{}) (result PaymentReversalBuilder) {
+	payment, err := record.payment()
+	if err != nil {
+		result.Err = err
+		return
+	}
+
+	result.P = xdr.PaymentReversalOp{
+		PaymentSource:    record.source(),
+		Asset:            payment.Asset,
+		Amount:           payment.Amount,
+		CommissionAmount: record.commission(),
+		PaymentId:        xdr.Int64(record.ID),
+	}
+
+	destination := payment.Destination
+	result.O.SourceAccount = &destination
+
+	result.Mutate(muts...)
+	if result.Err != nil {
+		return
+	}
+
+	result.Err = record.check(result)
+	return
+}
+
+// payment returns the payment operation of the record, ensuring it has been
+// successfully applied.
+func (r PaymentRecord) payment() (xdr.PaymentOp, error) {
+	ops := r.Envelope.Tx.Operations
+	if r.OpIndex < 0 || r.OpIndex >= len(ops) {
+		return xdr.PaymentOp{}, errors.New("Operation index out of range")
+	}
+
+	payment, ok := ops[r.OpIndex].Body.GetPaymentOp()
+	if !ok {
+		return xdr.PaymentOp{}, errors.New("Operation is not a payment")
+	}
+
+	results, ok := r.Result.Result.GetResults()
+	if r.Result.Result.Code != xdr.TransactionResultCodeTxSuccess || !ok || r.OpIndex >= len(results) {
+		return xdr.PaymentOp{}, errors.New("Payment transaction was not successful")
+	}
+
+	tr, ok := results[r.OpIndex].GetTr()
+	if !ok {
+		return xdr.PaymentOp{}, errors.New("Payment was not successful")
+	}
+
+	paymentResult, ok := tr.GetPaymentResult()
+	if !ok || paymentResult.Code != xdr.PaymentResultCodePaymentSuccess {
+		return xdr.PaymentOp{}, errors.New("Payment was not successful")
+	}
+
+	err := r.checkApplied(payment)
+	if err != nil {
+		return xdr.PaymentOp{}, err
+	}
+
+	return payment, nil
+}
+
+// checkApplied ensures that the meta of the payment operation records a change
+// to the balance of its destination: its account for native payments, its
+// trust line otherwise.
+func (r PaymentRecord) checkApplied(payment xdr.PaymentOp) error {
+	if r.Meta.Operations == nil || r.OpIndex >= len(*r.Meta.Operations) {
+		return errors.New("Payment meta is missing")
+	}
+	ops := *r.Meta.Operations
+
+	var key xdr.LedgerKey
+	var err error
+	if payment.Asset.Type == xdr.AssetTypeAssetTypeNative {
+		err = key.SetAccount(payment.Destination)
+	} else {
+		err = key.SetTrustline(payment.Destination, payment.Asset)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, change := range ops[r.OpIndex].Changes {
+		if change.Type != xdr.LedgerEntryChangeTypeLedgerEntryCreated &&
+			change.Type != xdr.LedgerEntryChangeTypeLedgerEntryUpdated {
+			continue
+		}
+		if key.Equals(change.LedgerKey()) {
+			return nil
+		}
+	}
+
+	return errors.New("Payment was not applied to its destination")
+}
+
+// source returns the account the payment was sent from.
+func (r PaymentRecord) source() xdr.AccountId {
+	op := r.Envelope.Tx.Operations[r.OpIndex]
+	if op.SourceAccount != nil {
+		return *op.SourceAccount
+	}
+
+	return r.Envelope.Tx.SourceAccount
+}
+
+// commission returns the commission charged for the payment.
+func (r PaymentRecord) commission() xdr.Int64 {
+	if r.OpIndex >= len(r.Envelope.OperationFees) {
+		return 0
+	}
+
+	fee, ok := r.Envelope.OperationFees[r.OpIndex].GetFee()
+	if !ok {
+		return 0
+	}
+
+	return fee.AmountToCharge
+}
+
+// check ensures that the reversal built by `b` matches the payment.
+func (r PaymentRecord) check(b PaymentReversalBuilder) error {
+	payment, err := r.payment()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case b.P.Amount != payment.Amount:
+		return ErrReversalInvalidAmount
+	case b.P.CommissionAmount != r.commission():
+		return ErrReversalInvalidCommission
+	case !b.P.PaymentSource.Equals(r.source()):
+		return ErrReversalInvalidPaymentSender
+	case !b.P.Asset.Equals(payment.Asset):
+		return ErrReversalInvalidAsset
+	case b.O.SourceAccount == nil || !b.O.SourceAccount.Equals(payment.Destination):
+		return ErrReversalInvalidSource
+	}
+
+	return nil
+}
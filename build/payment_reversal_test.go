@@ -0,0 +1,166 @@
+package build
+
+import (
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PaymentReversalFor", func() {
+
+	var (
+		record  PaymentRecord
+		subject PaymentReversalBuilder
+		muts    []interface{}
+
+		sender      = "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+		destination = "GAWSI2JO2CF36Z43UGMUJCDQ2IMR5B3P5TMS7XM7NUTU3JHG3YJUDQXA"
+		issuer      = "GCPZJ3MJQ3GUGJSBL6R3MLYZS6FKVHG67BPAINMXL3NWNXR5S6XG657P"
+	)
+
+	successfulResult := func(code xdr.PaymentResultCode) xdr.TransactionResult {
+		tr, err := xdr.NewOperationResultTr(xdr.OperationTypePayment, xdr.PaymentResult{Code: code})
+		Expect(err).NotTo(HaveOccurred())
+		opResult, err := xdr.NewOperationResult(xdr.OperationResultCodeOpInner, tr)
+		Expect(err).NotTo(HaveOccurred())
+		txResult, err := xdr.NewTransactionResultResult(xdr.TransactionResultCodeTxSuccess, []xdr.OperationResult{opResult})
+		Expect(err).NotTo(HaveOccurred())
+		return xdr.TransactionResult{Result: txResult}
+	}
+
+	// creditedMeta returns the meta of a transaction whose single operation
+	// updated the USD trust line of account
+	creditedMeta := func(account string) xdr.TransactionMeta {
+		var aid xdr.AccountId
+		Expect(aid.SetAddress(account)).To(Succeed())
+		asset, err := CreditAsset("USD", issuer).ToXdrObject()
+		Expect(err).NotTo(HaveOccurred())
+		entry := xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeTrustline,
+				TrustLine: &xdr.TrustLineEntry{
+					AccountId: aid,
+					Asset:     asset,
+					Balance:   1000000000,
+				},
+			},
+		}
+		ops := []xdr.OperationMeta{{Changes: xdr.LedgerEntryChanges{{
+			Type:    xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+			Updated: &entry,
+		}}}}
+		return xdr.TransactionMeta{Operations: &ops}
+	}
+
+	BeforeEach(func() {
+		txe := Transaction(
+			SourceAccount{sender},
+			Sequence{1},
+			Payment(Destination{destination}, CreditAmount{"USD", issuer, "100"}),
+		).Sign()
+		txe.Mutate(OperationFee{Asset: CreditAsset("USD", issuer), Amount: "1.5"})
+		Expect(txe.Err).NotTo(HaveOccurred())
+
+		record = PaymentRecord{
+			ID:       12884905985,
+			Envelope: *txe.E,
+			Result:   successfulResult(xdr.PaymentResultCodePaymentSuccess),
+			Meta:     creditedMeta(destination),
+			OpIndex:  0,
+		}
+		muts = nil
+	})
+
+	JustBeforeEach(func() { subject = PaymentReversalFor(record, muts...) })
+
+	Context("with a successful payment", func() {
+		It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+
+		It("populates the reversal from the payment", func() {
+			Expect(subject.P.PaymentSource.Address()).To(Equal(sender))
+			Expect(subject.P.Amount).To(Equal(xdr.Int64(1000000000)))
+			Expect(subject.P.CommissionAmount).To(Equal(xdr.Int64(15000000)))
+			Expect(subject.P.PaymentId).To(Equal(xdr.Int64(12884905985)))
+			Expect(subject.P.Asset.Type).To(Equal(xdr.AssetTypeAssetTypeCreditAlphanum4))
+			Expect(subject.O.SourceAccount.Address()).To(Equal(destination))
+		})
+
+		It("can be added to a transaction", func() {
+			tx := Transaction(SourceAccount{destination}, Sequence{1}, subject)
+			Expect(tx.Err).NotTo(HaveOccurred())
+			Expect(tx.TX.Operations[0].Body.Type).To(Equal(xdr.OperationTypePaymentReversal))
+		})
+	})
+
+	Context("with an operation payment source", func() {
+		BeforeEach(func() {
+			var source xdr.AccountId
+			Expect(source.SetAddress(issuer)).To(Succeed())
+			record.Envelope.Tx.Operations[0].SourceAccount = &source
+		})
+
+		It("uses the operation source as the payment sender", func() {
+			Expect(subject.P.PaymentSource.Address()).To(Equal(issuer))
+		})
+	})
+
+	Context("without an operation fee", func() {
+		BeforeEach(func() { record.Envelope.OperationFees = nil })
+		It("reverses no commission", func() {
+			Expect(subject.Err).NotTo(HaveOccurred())
+			Expect(subject.P.CommissionAmount).To(Equal(xdr.Int64(0)))
+		})
+	})
+
+	Context("with a mismatching amount", func() {
+		BeforeEach(func() { muts = []interface{}{CreditAmount{"USD", issuer, "10"}} })
+		It("failed", func() { Expect(subject.Err).To(Equal(ErrReversalInvalidAmount)) })
+	})
+
+	Context("with a mismatching asset", func() {
+		BeforeEach(func() { muts = []interface{}{CreditAmount{"EUR", issuer, "100"}} })
+		It("failed", func() { Expect(subject.Err).To(Equal(ErrReversalInvalidAsset)) })
+	})
+
+	Context("with a mismatching commission", func() {
+		BeforeEach(func() { muts = []interface{}{CommissionAmount{"1"}} })
+		It("failed", func() { Expect(subject.Err).To(Equal(ErrReversalInvalidCommission)) })
+	})
+
+	Context("with a mismatching payment sender", func() {
+		BeforeEach(func() { muts = []interface{}{PaymentSender{issuer}} })
+		It("failed", func() { Expect(subject.Err).To(Equal(ErrReversalInvalidPaymentSender)) })
+	})
+
+	Context("with a mismatching source", func() {
+		BeforeEach(func() { muts = []interface{}{SourceAccount{sender}} })
+		It("failed", func() { Expect(subject.Err).To(Equal(ErrReversalInvalidSource)) })
+	})
+
+	Context("with a failed payment", func() {
+		BeforeEach(func() { record.Result = successfulResult(xdr.PaymentResultCodePaymentUnderfunded) })
+		It("failed", func() { Expect(subject.Err).To(MatchError("Payment was not successful")) })
+	})
+
+	Context("without meta", func() {
+		BeforeEach(func() { record.Meta = xdr.TransactionMeta{} })
+		It("failed", func() { Expect(subject.Err).To(MatchError("Payment meta is missing")) })
+	})
+
+	Context("with meta not crediting the destination", func() {
+		BeforeEach(func() { record.Meta = creditedMeta(sender) })
+		It("failed", func() { Expect(subject.Err).To(MatchError("Payment was not applied to its destination")) })
+	})
+
+	Context("with an operation index out of range", func() {
+		BeforeEach(func() { record.OpIndex = 1 })
+		It("failed", func() { Expect(subject.Err).To(MatchError("Operation index out of range")) })
+	})
+
+	Context("with an operation that is not a payment", func() {
+		BeforeEach(func() {
+			record.Envelope.Tx.Operations[0].Body, _ = xdr.NewOperationBody(xdr.OperationTypeInflation, nil)
+		})
+		It("failed", func() { Expect(subject.Err).To(MatchError("Operation is not a payment")) })
+	})
+})
@@ -25,3 +25,16 @@ func (sp *MockSequenceProvider) SequenceForAccount(
 
 	return ret, nil
 }
+
+// MockBaseFeeProvider is a mock base fee provider.
+type MockBaseFeeProvider struct {
+	Fee uint32
+	Err error
+}
+
+var _ BaseFeeProvider = &MockBaseFeeProvider{}
+
+// BaseFee implements `BaseFeeProvider`
+func (p *MockBaseFeeProvider) BaseFee() (uint32, error) {
+	return p.Fee, p.Err
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
+	"time"
 
 	"bitbucket.org/atticlab/go-smart-base/hash"
 	"bitbucket.org/atticlab/go-smart-base/xdr"
@@ -19,6 +21,22 @@ func Transaction(muts ...TransactionMutator) (result *TransactionBuilder) {
 	return
 }
 
+// TransactionFromXDR creates a TransactionBuilder from the provided
+// base64-encoded xdr.Transaction, for use on the provided network.  Defaults
+// are not applied; the transaction is kept as it was encoded.
+func TransactionFromXDR(data string, network Network) (result *TransactionBuilder) {
+	result = &TransactionBuilder{TX: &xdr.Transaction{}}
+
+	err := xdr.SafeUnmarshalBase64(data, result.TX)
+	if err != nil {
+		result.Err = err
+		return
+	}
+
+	result.Mutate(network)
+	return
+}
+
 // TransactionMutator is a interface that wraps the
 // MutateTransaction operation.  types may implement this interface to
 // specify how they modify an xdr.Transaction object
@@ -31,6 +49,9 @@ type TransactionBuilder struct {
 	TX        *xdr.Transaction
 	NetworkID [32]byte
 	Err       error
+
+	// baseFee is the fee per operation set by the BaseFee mutator, if any
+	baseFee *uint32
 }
 
 // Mutate applies the provided TransactionMutators to this builder's transaction
@@ -137,6 +158,17 @@ func (m AllowTrustBuilder) MutateTransaction(o *TransactionBuilder) error {
 	return m.Err
 }
 
+// MutateTransaction for AutoBaseFee loads the base fee and sets it as the fee
+// per operation used to calculate the transaction's fee.
+func (m AutoBaseFee) MutateTransaction(o *TransactionBuilder) error {
+	baseFee, err := m.BaseFee()
+	if err != nil {
+		return err
+	}
+
+	return BaseFee(baseFee).MutateTransaction(o)
+}
+
 // MutateTransaction for AutoSequence loads the sequence and sets it on the tx.
 // NOTE:  this mutator assumes that the source account has already been set on
 // the transaction and will error if that has not occurred.
@@ -156,6 +188,14 @@ func (m AutoSequence) MutateTransaction(o *TransactionBuilder) error {
 	return nil
 }
 
+// MutateTransaction for BaseFee sets the fee per operation used to calculate
+// the transaction's fee.
+func (m BaseFee) MutateTransaction(o *TransactionBuilder) error {
+	baseFee := uint32(m)
+	o.baseFee = &baseFee
+	return nil
+}
+
 // MutateTransaction for ChangeTrustBuilder causes the underylying
 // CreateAccountOp to be added to the operation list for the provided
 // transaction
@@ -186,7 +226,17 @@ func (m CreateAccountBuilder) MutateTransaction(o *TransactionBuilder) error {
 func (m Defaults) MutateTransaction(o *TransactionBuilder) error {
 
 	if o.TX.Fee == 0 {
-		o.TX.Fee = xdr.Uint32(0 * len(o.TX.Operations))
+		baseFee := DefaultBaseFee
+		if o.baseFee != nil {
+			baseFee = *o.baseFee
+		}
+
+		fee := uint64(baseFee) * uint64(len(o.TX.Operations))
+		if fee > math.MaxUint32 {
+			return errors.New("Transaction fee overflows uint32")
+		}
+
+		o.TX.Fee = xdr.Uint32(fee)
 	}
 
 	if o.NetworkID == [32]byte{} {
@@ -195,6 +245,25 @@ func (m Defaults) MutateTransaction(o *TransactionBuilder) error {
 	return nil
 }
 
+// MutateTransaction for ExternalPaymentBuilder causes the underylying
+// ExternalPaymentOp to be added to the operation list for the provided
+// transaction
+func (m ExternalPaymentBuilder) MutateTransaction(o *TransactionBuilder) error {
+	if m.Err != nil {
+		return m.Err
+	}
+
+	m.O.Body, m.Err = xdr.NewOperationBody(xdr.OperationTypeExternalPayment, m.EP)
+	o.TX.Operations = append(o.TX.Operations, m.O)
+	return m.Err
+}
+
+// MutateTransaction for Fee sets the transaction's total fee.
+func (m Fee) MutateTransaction(o *TransactionBuilder) error {
+	o.TX.Fee = xdr.Uint32(m)
+	return nil
+}
+
 // MutateTransaction for InflationBuilder causes the underylying
 // InflationOp to be added to the operation list for the provided
 // transaction
@@ -324,6 +393,37 @@ func (m Sequence) MutateTransaction(o *TransactionBuilder) error {
 	return nil
 }
 
+// MutateTransaction for TimeBounds sets the TimeBounds on the transaction.
+func (m TimeBounds) MutateTransaction(o *TransactionBuilder) error {
+	if m.MaxTime != 0 && m.MinTime > m.MaxTime {
+		return errors.New("Invalid time bounds: min time is after max time")
+	}
+
+	o.TX.TimeBounds = &xdr.TimeBounds{
+		MinTime: xdr.Uint64(m.MinTime),
+		MaxTime: xdr.Uint64(m.MaxTime),
+	}
+	return nil
+}
+
+// MutateTransaction for Timeout sets the transaction's MaxTime to the current
+// time plus the timeout, preserving any MinTime already set.
+func (m Timeout) MutateTransaction(o *TransactionBuilder) error {
+	if m <= 0 {
+		return errors.New("Timeout must be positive")
+	}
+
+	bounds := TimeBounds{
+		MaxTime: uint64(time.Now().Add(time.Duration(m)).Unix()),
+	}
+
+	if o.TX.TimeBounds != nil {
+		bounds.MinTime = uint64(o.TX.TimeBounds.MinTime)
+	}
+
+	return bounds.MutateTransaction(o)
+}
+
 // MutateTransaction for SourceAccount sets the transaction's SourceAccount
 // to the pubilic key for the address provided
 func (m SourceAccount) MutateTransaction(o *TransactionBuilder) error {
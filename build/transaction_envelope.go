@@ -23,6 +23,26 @@ type TransactionEnvelopeBuilder struct {
 	child *TransactionBuilder
 }
 
+// EnvelopeFromXDR creates a TransactionEnvelopeBuilder from the provided
+// base64-encoded xdr.TransactionEnvelope, for use on the provided network.
+// The envelope's existing signatures and operation fees are preserved, so
+// further signatures may be added using the Sign mutator.
+func EnvelopeFromXDR(data string, network Network) (result *TransactionEnvelopeBuilder) {
+	result = &TransactionEnvelopeBuilder{E: &xdr.TransactionEnvelope{}}
+
+	err := xdr.SafeUnmarshalBase64(data, result.E)
+	if err != nil {
+		result.Err = err
+		return
+	}
+
+	result.Init()
+	result.MutateTX(network)
+	return
+}
+
+// Init initializes the builder's envelope and the builder for its underlying
+// transaction, if they are not already set.
 func (b *TransactionEnvelopeBuilder) Init() {
 	if b.E == nil {
 		b.E = &xdr.TransactionEnvelope{}
@@ -60,6 +80,13 @@ func (b *TransactionEnvelopeBuilder) MutateTX(muts ...TransactionMutator) {
 	b.Err = b.child.Err
 }
 
+// Transaction returns the builder for the envelope's underlying transaction.
+// Mutations made through it are reflected in the envelope.
+func (b *TransactionEnvelopeBuilder) Transaction() *TransactionBuilder {
+	b.Init()
+	return b.child
+}
+
 // Bytes encodes the builder's underlying envelope to XDR
 func (b *TransactionEnvelopeBuilder) Bytes() ([]byte, error) {
 	if b.Err != nil {
@@ -90,6 +117,11 @@ func (b *TransactionEnvelopeBuilder) Base64() (string, error) {
 
 // MutateTransactionEnvelope adds a signature to the provided envelope
 func (m Sign) MutateTransactionEnvelope(txe *TransactionEnvelopeBuilder) error {
+	err := validateOperationFees(txe.E)
+	if err != nil {
+		return err
+	}
+
 	hash, err := txe.child.Hash()
 
 	if err != nil {
@@ -53,3 +53,66 @@ var _ = Describe("TransactionEnvelope Mutators:", func() {
 	})
 
 })
+
+var _ = Describe("EnvelopeFromXDR", func() {
+	const seed = "SDOTALIMPAM2IV65IOZA7KZL7XWZI5BODFXTRVLIHLQZQCKK57PH5F3H"
+
+	var (
+		data    string
+		subject *TransactionEnvelopeBuilder
+	)
+
+	BeforeEach(func() {
+		original := TransactionEnvelopeBuilder{}
+		original.MutateTX(
+			SourceAccount{seed},
+			Sequence{10},
+			TestNetwork,
+			Payment(
+				Destination{"GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"},
+				NativeAmount{"10"},
+			),
+		)
+		original.Mutate(
+			OperationFee{Asset: NativeAsset(), Amount: "0.1"},
+			Sign{seed},
+		)
+
+		var err error
+		data, err = original.Base64()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() { subject = EnvelopeFromXDR(data, TestNetwork) })
+
+	Context("with a valid envelope", func() {
+		It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+		It("preserves the transaction", func() {
+			Expect(subject.E.Tx.SeqNum).To(BeEquivalentTo(10))
+			Expect(subject.E.Tx.Operations).To(HaveLen(1))
+		})
+		It("preserves the signatures", func() { Expect(subject.E.Signatures).To(HaveLen(1)) })
+		It("preserves the operation fees", func() { Expect(subject.E.OperationFees).To(HaveLen(1)) })
+		It("sets the network", func() {
+			Expect(subject.Transaction().NetworkID).To(Equal(TestNetwork.ID()))
+		})
+
+		It("re-encodes to the same envelope", func() {
+			reencoded, err := subject.Base64()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reencoded).To(Equal(data))
+		})
+
+		It("signs the same transaction hash", func() {
+			subject.Mutate(Sign{seed})
+			Expect(subject.Err).NotTo(HaveOccurred())
+			Expect(subject.E.Signatures).To(HaveLen(2))
+			Expect(subject.E.Signatures[1]).To(Equal(subject.E.Signatures[0]))
+		})
+	})
+
+	Context("with invalid base64", func() {
+		BeforeEach(func() { data = "not-base64!" })
+		It("fails", func() { Expect(subject.Err).To(HaveOccurred()) })
+	})
+})
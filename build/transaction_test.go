@@ -1,6 +1,9 @@
 package build
 
 import (
+	"errors"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"bitbucket.org/atticlab/go-smart-base"
@@ -23,12 +26,43 @@ var _ = Describe("Transaction Mutators:", func() {
 			mut = Defaults{}
 		})
 		It("sets the network id", func() { Expect(subject.NetworkID).To(Equal(DefaultNetwork.ID())) })
+		It("sets the fee from the default base fee", func() {
+			Expect(subject.TX.Fee).To(BeEquivalentTo(DefaultBaseFee))
+		})
 
 		Context("on a transaction with 2 operations", func() {
 			BeforeEach(func() { subject.Mutate(Payment()) })
+
+			Context("with a base fee set", func() {
+				BeforeEach(func() { subject.Mutate(BaseFee(100)) })
+				It("scales the fee with the operation count", func() {
+					Expect(subject.TX.Fee).To(BeEquivalentTo(200))
+				})
+			})
+
+			Context("with a fee set", func() {
+				BeforeEach(func() { subject.Mutate(BaseFee(100), Fee(150)) })
+				It("keeps the fee", func() { Expect(subject.TX.Fee).To(BeEquivalentTo(150)) })
+			})
+
+			Context("with a base fee that overflows the fee", func() {
+				BeforeEach(func() { subject.Mutate(BaseFee(1 << 31)) })
+				It("fails", func() { Expect(subject.Err).To(HaveOccurred()) })
+			})
 		})
 	})
 
+	Describe("BaseFee", func() {
+		BeforeEach(func() { mut = BaseFee(100) })
+		It("does not set the fee", func() { Expect(subject.TX.Fee).To(BeEquivalentTo(0)) })
+		It("sets the base fee", func() { Expect(*subject.baseFee).To(BeEquivalentTo(100)) })
+	})
+
+	Describe("Fee", func() {
+		BeforeEach(func() { mut = Fee(300) })
+		It("sets the fee", func() { Expect(subject.TX.Fee).To(BeEquivalentTo(300)) })
+	})
+
 	Describe("MemoHash", func() {
 		BeforeEach(func() { mut = MemoHash{[32]byte{0x01}} })
 		It("sets a Hash memo on the transaction", func() {
@@ -110,6 +144,50 @@ var _ = Describe("Transaction Mutators:", func() {
 		It("sets the sequence", func() { Expect(subject.TX.SeqNum).To(BeEquivalentTo(12345)) })
 	})
 
+	Describe("TimeBounds", func() {
+		Context("with valid bounds", func() {
+			BeforeEach(func() { mut = TimeBounds{MinTime: 1000, MaxTime: 2000} })
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("sets the time bounds", func() {
+				Expect(subject.TX.TimeBounds.MinTime).To(BeEquivalentTo(1000))
+				Expect(subject.TX.TimeBounds.MaxTime).To(BeEquivalentTo(2000))
+			})
+		})
+
+		Context("without an upper bound", func() {
+			BeforeEach(func() { mut = TimeBounds{MinTime: 1000} })
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+		})
+
+		Context("with min time after max time", func() {
+			BeforeEach(func() { mut = TimeBounds{MinTime: 2000, MaxTime: 1000} })
+			It("fails", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
+	Describe("Timeout", func() {
+		Context("with a positive duration", func() {
+			BeforeEach(func() {
+				subject.Mutate(TimeBounds{MinTime: 1000})
+				mut = Timeout(5 * time.Minute)
+			})
+
+			It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+			It("sets the max time", func() {
+				expected := time.Now().Add(5 * time.Minute).Unix()
+				Expect(int64(subject.TX.TimeBounds.MaxTime)).To(BeNumerically("~", expected, 1))
+			})
+			It("keeps the min time", func() {
+				Expect(subject.TX.TimeBounds.MinTime).To(BeEquivalentTo(1000))
+			})
+		})
+
+		Context("with a non-positive duration", func() {
+			BeforeEach(func() { mut = Timeout(0) })
+			It("fails", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+
 	Describe("AutoSequence", func() {
 		BeforeEach(func() {
 			mock := &MockSequenceProvider{
@@ -136,4 +214,56 @@ var _ = Describe("Transaction Mutators:", func() {
 			It("sets the sequence", func() { Expect(subject.TX.SeqNum).To(BeEquivalentTo(3)) })
 		})
 	})
+	Describe("AutoBaseFee", func() {
+		var mock *MockBaseFeeProvider
+
+		BeforeEach(func() {
+			mock = &MockBaseFeeProvider{Fee: 100}
+			mut = AutoBaseFee{mock}
+		})
+
+		It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+		It("sets the base fee", func() { Expect(*subject.baseFee).To(BeEquivalentTo(100)) })
+
+		Context("when the provider fails", func() {
+			BeforeEach(func() { mock.Err = errors.New("horizon is down") })
+			It("fails", func() { Expect(subject.Err).To(HaveOccurred()) })
+		})
+	})
+})
+
+var _ = Describe("TransactionFromXDR", func() {
+	var (
+		data    string
+		subject *TransactionBuilder
+	)
+
+	BeforeEach(func() {
+		original := Transaction(
+			SourceAccount{"GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"},
+			Sequence{10},
+			Fee(300),
+			TestNetwork,
+		)
+
+		var err error
+		data, err = xdr.MarshalBase64(original.TX)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() { subject = TransactionFromXDR(data, TestNetwork) })
+
+	Context("with a valid transaction", func() {
+		It("succeeds", func() { Expect(subject.Err).NotTo(HaveOccurred()) })
+		It("preserves the transaction", func() {
+			Expect(subject.TX.SeqNum).To(BeEquivalentTo(10))
+			Expect(subject.TX.Fee).To(BeEquivalentTo(300))
+		})
+		It("sets the network", func() { Expect(subject.NetworkID).To(Equal(TestNetwork.ID())) })
+	})
+
+	Context("with invalid xdr", func() {
+		BeforeEach(func() { data = "AAAA" })
+		It("fails", func() { Expect(subject.Err).To(HaveOccurred()) })
+	})
 })
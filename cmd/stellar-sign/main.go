@@ -27,13 +27,26 @@ func main() {
 	}
 
 	// parse the envelope
-	var txe xdr.TransactionEnvelope
-	err = xdr.SafeUnmarshalBase64(env, &txe)
+	b := build.EnvelopeFromXDR(strings.TrimSpace(env), build.PublicNetwork)
+	if b.Err != nil {
+		log.Fatal(b.Err)
+	}
+
+	// print transaction details
+	details, err := xdr.MarshalTxRep(b.E)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// TODO: print transaction details
+	fmt.Print("\n==== Transaction ====\n\n")
+	fmt.Println(details)
+
+	if tb := b.E.Tx.TimeBounds; tb != nil {
+		fmt.Printf("Valid from: %s\n", tb.MinTimeAt())
+		if max, ok := tb.MaxTimeAt(); ok {
+			fmt.Printf("Valid until: %s\n", max)
+		}
+	}
 
 	// read seed
 	seed, err := readLine("Enter seed: ", true)
@@ -42,15 +55,8 @@ func main() {
 	}
 
 	// sign the transaction
-	b := &build.TransactionEnvelopeBuilder{E: &txe}
-	b.Init()
-	b.MutateTX(build.PublicNetwork)
 	b.Mutate(build.Sign{seed})
-	if b.Err != nil {
-		log.Fatal(b.Err)
-	}
-
-	newEnv, err := xdr.MarshalBase64(b.E)
+	newEnv, err := b.Base64()
 	if err != nil {
 		log.Fatal(err)
 	}
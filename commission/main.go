@@ -0,0 +1,172 @@
+// Package commission models the commissions charged by the payment network
+// and provides helpers to calculate them client-side.
+//
+// A Schedule holds a set of Rules.  Each rule may be keyed by asset, by the
+// account types of the sender and the receiver and by specific sender and
+// receiver accounts.  When calculating the commission for a payment the most
+// specific matching rule is used.  Commissions are made of a percent part and
+// a flat part, both expressed using the convention of the amount package, so
+// that a PercentFee of amount.MustParse("1.5") is one and a half percent.
+package commission
+
+import (
+	"errors"
+	"math"
+	"math/big"
+
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// Weights used to rank matching rules.  A rule keyed by a specific account
+// is always more specific than one keyed by account types and asset alone.
+const (
+	assetWeight       = 1
+	accountTypeWeight = 2
+	accountWeight     = 8
+)
+
+// Rule describes the commission charged on payments matching its key.  Key
+// fields left nil match any value.
+type Rule struct {
+	Asset    *xdr.Asset
+	FromType *xdr.AccountType
+	ToType   *xdr.AccountType
+	From     *xdr.AccountId
+	To       *xdr.AccountId
+
+	PercentFee xdr.Int64
+	FlatFee    xdr.Int64
+}
+
+// Payment describes a transfer a commission may be charged on.
+type Payment struct {
+	Asset    xdr.Asset
+	Amount   xdr.Int64
+	From     xdr.AccountId
+	FromType xdr.AccountType
+	To       xdr.AccountId
+	ToType   xdr.AccountType
+}
+
+// Schedule is a set of commission rules.
+type Schedule struct {
+	Rules []Rule
+}
+
+// Add appends the rule to the schedule.
+func (s *Schedule) Add(r Rule) error {
+	if r.PercentFee < 0 || r.FlatFee < 0 {
+		return errors.New("commission: fees can't be negative")
+	}
+
+	s.Rules = append(s.Rules, r)
+	return nil
+}
+
+// Find returns the most specific rule matching `p`.  When several rules are
+// equally specific the one added first wins.
+func (s *Schedule) Find(p Payment) (result Rule, ok bool) {
+	best := -1
+
+	for _, r := range s.Rules {
+		score, matched := r.match(p)
+		if !matched || score <= best {
+			continue
+		}
+
+		best = score
+		result = r
+		ok = true
+	}
+
+	return
+}
+
+// Calculate returns the commission charged on `p` according to the most
+// specific matching rule.  A payment matching no rule is charged nothing.
+func (s *Schedule) Calculate(p Payment) (xdr.Int64, error) {
+	r, ok := s.Find(p)
+	if !ok {
+		return 0, nil
+	}
+
+	return r.Fee(p.Amount)
+}
+
+// Fee returns the commission charged by this rule on `paymentAmount`.
+func (r Rule) Fee(paymentAmount xdr.Int64) (xdr.Int64, error) {
+	return Calculate(paymentAmount, r.PercentFee, r.FlatFee)
+}
+
+// Calculate returns paymentAmount * percentFee / 100 + flatFee.  The percent
+// part is rounded down to the nearest stroop.
+func Calculate(paymentAmount, percentFee, flatFee xdr.Int64) (xdr.Int64, error) {
+	if paymentAmount < 0 {
+		return 0, errors.New("commission: payment amount can't be negative")
+	}
+
+	if percentFee < 0 || flatFee < 0 {
+		return 0, errors.New("commission: fees can't be negative")
+	}
+
+	fee := new(big.Int).Mul(big.NewInt(int64(paymentAmount)), big.NewInt(int64(percentFee)))
+	fee.Quo(fee, big.NewInt(100*amount.One))
+	fee.Add(fee, big.NewInt(int64(flatFee)))
+
+	if fee.Cmp(big.NewInt(math.MaxInt64)) > 0 {
+		return 0, errors.New("commission: fee overflows int64")
+	}
+
+	return xdr.Int64(fee.Int64()), nil
+}
+
+// match reports whether `p` matches the rule and, if so, how specific the
+// match is.
+func (r Rule) match(p Payment) (score int, ok bool) {
+	if r.Asset != nil {
+		if !r.Asset.Equals(p.Asset) {
+			return
+		}
+		score += assetWeight
+	}
+
+	if r.FromType != nil {
+		if *r.FromType != p.FromType {
+			return
+		}
+		score += accountTypeWeight
+	}
+
+	if r.ToType != nil {
+		if *r.ToType != p.ToType {
+			return
+		}
+		score += accountTypeWeight
+	}
+
+	if r.From != nil {
+		if !sameAccount(r.From, p.From) {
+			return
+		}
+		score += accountWeight
+	}
+
+	if r.To != nil {
+		if !sameAccount(r.To, p.To) {
+			return
+		}
+		score += accountWeight
+	}
+
+	ok = true
+	return
+}
+
+func sameAccount(aid *xdr.AccountId, other xdr.AccountId) bool {
+	if other == (xdr.AccountId{}) {
+		return false
+	}
+
+	return aid.Equals(other)
+}
@@ -0,0 +1,138 @@
+package commission
+
+import (
+	"testing"
+
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCommission(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Package: bitbucket.org/atticlab/go-smart-base/commission")
+}
+
+var _ = Describe("commission.Calculate", func() {
+	It("combines the percent and flat parts", func() {
+		fee, err := Calculate(amount.MustParse("200"), amount.MustParse("1.5"), amount.MustParse("0.25"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(amount.String(fee)).To(Equal("3.2500000"))
+	})
+
+	It("rounds the percent part down", func() {
+		fee, err := Calculate(99, amount.MustParse("10"), 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fee).To(Equal(xdr.Int64(9)))
+	})
+
+	It("fails on negative values", func() {
+		_, err := Calculate(-1, 0, 0)
+		Expect(err).To(HaveOccurred())
+		_, err = Calculate(1, -1, 0)
+		Expect(err).To(HaveOccurred())
+		_, err = Calculate(1, 0, -1)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails on overflow", func() {
+		_, err := Calculate(amount.MustParse("900000000000"), amount.MustParse("100"), amount.MustParse("900000000000"))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("commission.Schedule", func() {
+	var (
+		subject Schedule
+
+		sender   xdr.AccountId
+		receiver xdr.AccountId
+		issuer   xdr.AccountId
+		usd      xdr.Asset
+		eur      xdr.Asset
+
+		merchant   = xdr.AccountTypeAccountMerchant
+		registered = xdr.AccountTypeAccountRegisteredUser
+	)
+
+	BeforeEach(func() {
+		Expect(sender.SetAddress("GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ")).To(Succeed())
+		Expect(receiver.SetAddress("GAWSI2JO2CF36Z43UGMUJCDQ2IMR5B3P5TMS7XM7NUTU3JHG3YJUDQXA")).To(Succeed())
+		Expect(issuer.SetAddress("GCPZJ3MJQ3GUGJSBL6R3MLYZS6FKVHG67BPAINMXL3NWNXR5S6XG657P")).To(Succeed())
+		Expect(usd.SetCredit("USD", issuer)).To(Succeed())
+		Expect(eur.SetCredit("EUR", issuer)).To(Succeed())
+
+		subject = Schedule{}
+		Expect(subject.Add(Rule{FlatFee: amount.MustParse("1")})).To(Succeed())
+		Expect(subject.Add(Rule{Asset: &usd, FlatFee: amount.MustParse("2")})).To(Succeed())
+		Expect(subject.Add(Rule{Asset: &usd, ToType: &merchant, PercentFee: amount.MustParse("1")})).To(Succeed())
+		Expect(subject.Add(Rule{To: &receiver, FlatFee: amount.MustParse("0.5")})).To(Succeed())
+	})
+
+	payment := func(asset xdr.Asset, to xdr.AccountId, toType xdr.AccountType) Payment {
+		return Payment{
+			Asset:    asset,
+			Amount:   amount.MustParse("100"),
+			From:     sender,
+			FromType: registered,
+			To:       to,
+			ToType:   toType,
+		}
+	}
+
+	Describe("Add", func() {
+		It("rejects negative fees", func() {
+			Expect(subject.Add(Rule{FlatFee: -1})).NotTo(Succeed())
+			Expect(subject.Rules).To(HaveLen(4))
+		})
+	})
+
+	Describe("Calculate", func() {
+		It("uses the default rule when nothing more specific matches", func() {
+			fee, err := subject.Calculate(payment(eur, issuer, registered))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(amount.String(fee)).To(Equal("1.0000000"))
+		})
+
+		It("prefers an asset rule over the default rule", func() {
+			fee, err := subject.Calculate(payment(usd, issuer, registered))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(amount.String(fee)).To(Equal("2.0000000"))
+		})
+
+		It("prefers an account type rule over an asset rule", func() {
+			fee, err := subject.Calculate(payment(usd, issuer, merchant))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(amount.String(fee)).To(Equal("1.0000000"))
+		})
+
+		It("prefers an account rule over any other rule", func() {
+			fee, err := subject.Calculate(payment(usd, receiver, merchant))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(amount.String(fee)).To(Equal("0.5000000"))
+		})
+
+		It("charges nothing when no rule matches", func() {
+			empty := Schedule{}
+			fee, err := empty.Calculate(payment(usd, receiver, merchant))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fee).To(Equal(xdr.Int64(0)))
+		})
+	})
+
+	Describe("Find", func() {
+		It("uses the rule added first among equally specific rules", func() {
+			Expect(subject.Add(Rule{Asset: &usd, FlatFee: amount.MustParse("3")})).To(Succeed())
+			r, ok := subject.Find(payment(usd, issuer, registered))
+			Expect(ok).To(BeTrue())
+			Expect(r.FlatFee).To(Equal(amount.MustParse("2")))
+		})
+
+		It("does not match account rules against unset accounts", func() {
+			r, ok := subject.Find(Payment{Asset: eur})
+			Expect(ok).To(BeTrue())
+			Expect(r.FlatFee).To(Equal(amount.MustParse("1")))
+		})
+	})
+})
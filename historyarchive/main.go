@@ -0,0 +1,290 @@
+// Package historyarchive reads the history archives published by validators
+// and verifies their integrity, so that the history of a network can be
+// audited offline.
+//
+// Archives are read from local directories, or file URLs, laid out as stellar
+// core publishes them: history archive states (HAS) as JSON, checkpoint files
+// of ledger headers, transactions and results, and buckets, as gzip
+// compressed streams of xdr records.
+package historyarchive
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"bitbucket.org/atticlab/go-smart-base/hash"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// CheckpointFrequency is the number of ledgers between checkpoints.  The
+// checkpoint ledgers are the ones before multiples of it: 63, 127, 191...
+const CheckpointFrequency = 64
+
+// RootHASPath is the path of the history archive state of the last
+// checkpoint published to an archive
+const RootHASPath = ".well-known/stellar-history.json"
+
+// zeroBucket is the hash of empty buckets, which are not published
+const zeroBucket = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Archive represents a history archive stored in a local directory
+type Archive struct {
+	Root string
+}
+
+// HistoryArchiveState represents the state of the archive at a checkpoint:
+// the buckets of the bucket list after its last ledger
+type HistoryArchiveState struct {
+	Version        int           `json:"version"`
+	Server         string        `json:"server,omitempty"`
+	CurrentLedger  uint32        `json:"currentLedger"`
+	CurrentBuckets []BucketLevel `json:"currentBuckets"`
+}
+
+// BucketLevel represents a level of the bucket list, made of the hex encoded
+// hashes of its buckets
+type BucketLevel struct {
+	Curr string       `json:"curr"`
+	Snap string       `json:"snap"`
+	Next FutureBucket `json:"next"`
+}
+
+// FutureBucket represents a bucket being merged into a level.  Output is only
+// set once the merge is complete.
+type FutureBucket struct {
+	State  int    `json:"state"`
+	Output string `json:"output,omitempty"`
+}
+
+// Open returns the archive stored at the provided local path or file URL
+func Open(url string) (*Archive, error) {
+	root := url
+	if strings.Contains(url, "://") {
+		if !strings.HasPrefix(url, "file://") {
+			return nil, fmt.Errorf("Unsupported archive URL: %s", url)
+		}
+		root = strings.TrimPrefix(url, "file://")
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("Archive root %s is not a directory", root)
+	}
+
+	return &Archive{Root: root}, nil
+}
+
+// CheckpointContaining returns the checkpoint of the provided ledger
+func CheckpointContaining(ledger uint32) uint32 {
+	return ledger/CheckpointFrequency*CheckpointFrequency + CheckpointFrequency - 1
+}
+
+// CheckpointPath returns the path of the file of the provided category
+// ("history", "ledger", "transactions", "results" or "scp") and checkpoint,
+// relative to the root of archives
+func CheckpointPath(category string, checkpoint uint32) string {
+	ext := ".xdr.gz"
+	if category == "history" {
+		ext = ".json"
+	}
+
+	h := fmt.Sprintf("%08x", checkpoint)
+	return path.Join(category, h[0:2], h[2:4], h[4:6], category+"-"+h+ext)
+}
+
+// BucketPath returns the path of the bucket with the provided hex encoded
+// hash, relative to the root of archives
+func BucketPath(bucket string) string {
+	return path.Join("bucket", bucket[0:2], bucket[2:4], bucket[4:6], "bucket-"+bucket+".xdr.gz")
+}
+
+// GetRootHAS returns the state of the last checkpoint published to the
+// archive
+func (a *Archive) GetRootHAS() (HistoryArchiveState, error) {
+	return a.getHAS(RootHASPath)
+}
+
+// GetCheckpointHAS returns the state of the archive at the provided checkpoint
+func (a *Archive) GetCheckpointHAS(checkpoint uint32) (HistoryArchiveState, error) {
+	return a.getHAS(CheckpointPath("history", checkpoint))
+}
+
+// GetLedgerHeaders returns the headers of the ledgers of the provided
+// checkpoint
+func (a *Archive) GetLedgerHeaders(checkpoint uint32) (ret []xdr.LedgerHeaderHistoryEntry, err error) {
+	err = a.readStream(CheckpointPath("ledger", checkpoint), func(s *xdr.Stream) error {
+		var entry xdr.LedgerHeaderHistoryEntry
+		err := s.ReadOne(&entry)
+		if err == nil {
+			ret = append(ret, entry)
+		}
+		return err
+	})
+	return
+}
+
+// GetTransactions returns the transaction sets of the ledgers of the provided
+// checkpoint.  Ledgers without transactions may be omitted.
+func (a *Archive) GetTransactions(checkpoint uint32) (ret []xdr.TransactionHistoryEntry, err error) {
+	err = a.readStream(CheckpointPath("transactions", checkpoint), func(s *xdr.Stream) error {
+		var entry xdr.TransactionHistoryEntry
+		err := s.ReadOne(&entry)
+		if err == nil {
+			ret = append(ret, entry)
+		}
+		return err
+	})
+	return
+}
+
+// GetResults returns the transaction result sets of the ledgers of the
+// provided checkpoint.  Ledgers without transactions may be omitted.
+func (a *Archive) GetResults(checkpoint uint32) (ret []xdr.TransactionHistoryResultEntry, err error) {
+	err = a.readStream(CheckpointPath("results", checkpoint), func(s *xdr.Stream) error {
+		var entry xdr.TransactionHistoryResultEntry
+		err := s.ReadOne(&entry)
+		if err == nil {
+			ret = append(ret, entry)
+		}
+		return err
+	})
+	return
+}
+
+// ForEachBucketEntry calls fn with each entry of the bucket with the provided
+// hex encoded hash, one at a time, then verifies the hash of the bucket.
+// Iteration stops at the first error returned by fn.
+func (a *Archive) ForEachBucketEntry(bucket string, fn func(xdr.BucketEntry) error) error {
+	expected, err := hex.DecodeString(bucket)
+	if err != nil || len(expected) != 32 {
+		return fmt.Errorf("Invalid bucket hash: %s", bucket)
+	}
+
+	hasher := sha256.New()
+	err = a.readStream(BucketPath(bucket), func(s *xdr.Stream) error {
+		var entry xdr.BucketEntry
+		err := s.ReadOne(&entry)
+		if err != nil {
+			return err
+		}
+		return fn(entry)
+	}, hasher)
+	if err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != bucket {
+		return fmt.Errorf("Bucket %s has hash %s", bucket, actual)
+	}
+	return nil
+}
+
+// VerifyBucket reads the bucket with the provided hex encoded hash, checking
+// that it decodes and that its content matches its hash
+func (a *Archive) VerifyBucket(bucket string) error {
+	return a.ForEachBucketEntry(bucket, func(xdr.BucketEntry) error { return nil })
+}
+
+// Buckets returns the hashes of the non empty buckets of the state, including
+// the outputs of completed merges
+func (h HistoryArchiveState) Buckets() []string {
+	var ret []string
+	seen := map[string]bool{}
+	for _, level := range h.CurrentBuckets {
+		for _, bucket := range []string{level.Curr, level.Snap, level.Next.Output} {
+			if bucket == "" || bucket == zeroBucket || seen[bucket] {
+				continue
+			}
+			seen[bucket] = true
+			ret = append(ret, bucket)
+		}
+	}
+	return ret
+}
+
+// BucketListHash returns the hash of the bucket list of the state, as found
+// in the header of its last ledger: the hash of the hashes of each level,
+// themselves the hashes of their current and snapshot buckets.
+func (h HistoryArchiveState) BucketListHash() (ret xdr.Hash, err error) {
+	var levels []byte
+	for i, level := range h.CurrentBuckets {
+		var curr, snap []byte
+		curr, err = hex.DecodeString(level.Curr)
+		if err == nil {
+			snap, err = hex.DecodeString(level.Snap)
+		}
+		if err != nil || len(curr) != 32 || len(snap) != 32 {
+			err = fmt.Errorf("Invalid bucket hash in level %d", i)
+			return
+		}
+
+		levelHash := hash.Hash(append(curr, snap...))
+		levels = append(levels, levelHash[:]...)
+	}
+
+	ret = xdr.Hash(hash.Hash(levels))
+	return
+}
+
+func (a *Archive) getHAS(name string) (ret HistoryArchiveState, err error) {
+	f, err := os.Open(filepath.Join(a.Root, filepath.FromSlash(name)))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	err = json.NewDecoder(f).Decode(&ret)
+	if err != nil {
+		err = fmt.Errorf("Invalid history archive state %s: %s", name, err)
+	}
+	return
+}
+
+// readStream calls read until it returns io.EOF, with the stream of records
+// of the named gzip compressed file.  The decompressed content of the file is
+// also written to the provided writers.
+func (a *Archive) readStream(name string, read func(*xdr.Stream) error, ws ...io.Writer) error {
+	f, err := os.Open(filepath.Join(a.Root, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("Invalid archive file %s: %s", name, err)
+	}
+	defer gz.Close()
+
+	var r io.Reader = gz
+	if len(ws) > 0 {
+		r = io.TeeReader(gz, io.MultiWriter(ws...))
+	}
+
+	stream, err := xdr.NewStream(r)
+	if err != nil {
+		return fmt.Errorf("Invalid archive file %s: %s", name, err)
+	}
+	defer stream.Close()
+
+	for {
+		err = read(stream)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Invalid archive file %s: %s", name, err)
+		}
+	}
+}
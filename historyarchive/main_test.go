@@ -0,0 +1,306 @@
+package historyarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bitbucket.org/atticlab/go-smart-base/hash"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHistoryarchive(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Package: bitbucket.org/atticlab/go-smart-base/historyarchive")
+}
+
+var _ = Describe("Archive", func() {
+	const address = "GA3FR7TVTDJAY6TN4MUX7BF4KK6SUHWIYDY7NRNUDTA4OVY3IMY7B6H5"
+
+	var (
+		root    string
+		archive *Archive
+		bucket  string
+		tamper  func(header *xdr.LedgerHeader)
+	)
+
+	writeFile := func(name string, content []byte) {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		Expect(os.MkdirAll(filepath.Dir(p), 0755)).To(Succeed())
+
+		if filepath.Ext(name) == ".gz" {
+			var compressed bytes.Buffer
+			w := gzip.NewWriter(&compressed)
+			_, err := w.Write(content)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+			content = compressed.Bytes()
+		}
+
+		Expect(ioutil.WriteFile(p, content, 0644)).To(Succeed())
+	}
+
+	framed := func(values ...interface{}) []byte {
+		var raw bytes.Buffer
+		for _, v := range values {
+			_, err := xdr.MarshalFramed(&raw, v)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return raw.Bytes()
+	}
+
+	hashOf := func(v interface{}) xdr.Hash {
+		h, err := hashOf(v)
+		Expect(err).NotTo(HaveOccurred())
+		return h
+	}
+
+	// writeArchive writes two checkpoints, of ledgers 1 to 127, with
+	// transactions in ledgers 5 and 70 and a single bucket
+	writeArchive := func() {
+		var account xdr.AccountId
+		Expect(account.SetAddress(address)).To(Succeed())
+
+		content := framed(xdr.BucketEntry{
+			Type: xdr.BucketEntryTypeLiveentry,
+			LiveEntry: &xdr.LedgerEntry{
+				LastModifiedLedgerSeq: 5,
+				Data: xdr.LedgerEntryData{
+					Type:    xdr.LedgerEntryTypeAccount,
+					Account: &xdr.AccountEntry{AccountId: account, Balance: 100},
+				},
+			},
+		})
+		h := hash.Hash(content)
+		bucket = hex.EncodeToString(h[:])
+		writeFile(BucketPath(bucket), content)
+
+		has := HistoryArchiveState{
+			Version: 1,
+			CurrentBuckets: []BucketLevel{
+				{Curr: bucket, Snap: zeroBucket},
+				{Curr: zeroBucket, Snap: zeroBucket},
+			},
+		}
+		bucketListHash, err := has.BucketListHash()
+		Expect(err).NotTo(HaveOccurred())
+
+		var prev xdr.Hash
+		for _, checkpoint := range []uint32{63, 127} {
+			var headers, transactions, results []interface{}
+
+			for seq := CheckpointContaining(checkpoint) - 63; seq <= checkpoint; seq++ {
+				if seq == 0 {
+					continue
+				}
+
+				var resultSet xdr.TransactionResultSet
+				if seq == 5 || seq == 70 {
+					resultSet.Results = []xdr.TransactionResultPair{{
+						TransactionHash: xdr.Hash{byte(seq)},
+						Result: xdr.TransactionResult{
+							Result: xdr.TransactionResultResult{Code: xdr.TransactionResultCodeTxBadSeq},
+						},
+					}}
+					results = append(results, xdr.TransactionHistoryResultEntry{
+						LedgerSeq:   xdr.Uint32(seq),
+						TxResultSet: resultSet,
+					})
+					transactions = append(transactions, xdr.TransactionHistoryEntry{
+						LedgerSeq: xdr.Uint32(seq),
+						TxSet: xdr.TransactionSet{
+							PreviousLedgerHash: prev,
+							Txs: []xdr.TransactionEnvelope{{
+								Tx: xdr.Transaction{SourceAccount: account, Fee: 100, SeqNum: 1},
+							}},
+						},
+					})
+				}
+
+				header := xdr.LedgerHeader{
+					LedgerVersion:      1,
+					PreviousLedgerHash: prev,
+					TxSetResultHash:    hashOf(resultSet),
+					LedgerSeq:          xdr.Uint32(seq),
+					MaxTxSetSize:       50,
+				}
+				if seq == checkpoint {
+					header.BucketListHash = bucketListHash
+				}
+				if tamper != nil {
+					tamper(&header)
+				}
+
+				prev = hashOf(header)
+				headers = append(headers, xdr.LedgerHeaderHistoryEntry{Hash: prev, Header: header})
+			}
+
+			writeFile(CheckpointPath("ledger", checkpoint), framed(headers...))
+			writeFile(CheckpointPath("transactions", checkpoint), framed(transactions...))
+			writeFile(CheckpointPath("results", checkpoint), framed(results...))
+
+			has.CurrentLedger = checkpoint
+			raw, err := json.Marshal(has)
+			Expect(err).NotTo(HaveOccurred())
+			writeFile(CheckpointPath("history", checkpoint), raw)
+			writeFile(RootHASPath, raw)
+		}
+	}
+
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "historyarchive")
+		Expect(err).NotTo(HaveOccurred())
+		tamper = nil
+	})
+
+	JustBeforeEach(func() {
+		writeArchive()
+
+		var err error
+		archive, err = Open("file://" + root)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() { os.RemoveAll(root) })
+
+	It("lays out files like stellar core", func() {
+		Expect(CheckpointContaining(1)).To(BeEquivalentTo(63))
+		Expect(CheckpointContaining(64)).To(BeEquivalentTo(127))
+		Expect(CheckpointPath("ledger", 0x1234567f)).To(Equal("ledger/12/34/56/ledger-1234567f.xdr.gz"))
+		Expect(CheckpointPath("history", 63)).To(Equal("history/00/00/00/history-0000003f.json"))
+		Expect(BucketPath("abcdef01")).To(Equal("bucket/ab/cd/ef/bucket-abcdef01.xdr.gz"))
+	})
+
+	It("reads archives", func() {
+		has, err := archive.GetRootHAS()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(has.CurrentLedger).To(BeEquivalentTo(127))
+		Expect(has.Buckets()).To(Equal([]string{bucket}))
+
+		headers, err := archive.GetLedgerHeaders(63)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(headers).To(HaveLen(63))
+		Expect(headers[0].Header.LedgerSeq).To(BeEquivalentTo(1))
+
+		transactions, err := archive.GetTransactions(127)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transactions).To(HaveLen(1))
+		Expect(transactions[0].LedgerSeq).To(BeEquivalentTo(70))
+
+		results, err := archive.GetResults(63)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(HaveLen(1))
+
+		var entries []xdr.BucketEntry
+		err = archive.ForEachBucketEntry(bucket, func(e xdr.BucketEntry) error {
+			entries = append(entries, e)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].LiveEntry.Data.Account.Balance).To(BeEquivalentTo(100))
+	})
+
+	It("verifies intact archives", func() {
+		Expect(archive.Verify(1, 127)).To(Succeed())
+		Expect(archive.Verify(70, 70)).To(Succeed())
+	})
+
+	Context("when the ledger chain is broken", func() {
+		BeforeEach(func() {
+			tamper = func(header *xdr.LedgerHeader) {
+				if header.LedgerSeq == 64 {
+					header.PreviousLedgerHash = xdr.Hash{}
+				}
+			}
+		})
+
+		It("fails", func() {
+			Expect(archive.Verify(70, 70)).To(Succeed())
+			err := archive.Verify(1, 127)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Ledger 64 does not follow ledger 63"))
+		})
+	})
+
+	Context("when a result set does not match its header", func() {
+		BeforeEach(func() {
+			tamper = func(header *xdr.LedgerHeader) {
+				if header.LedgerSeq == 5 {
+					header.TxSetResultHash = xdr.Hash{}
+				}
+			}
+		})
+
+		It("fails", func() {
+			err := archive.Verify(1, 63)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Ledger 5 has result set hash"))
+		})
+	})
+
+	Context("when the bucket list does not match its header", func() {
+		BeforeEach(func() {
+			tamper = func(header *xdr.LedgerHeader) {
+				if header.LedgerSeq == 127 {
+					header.BucketListHash = xdr.Hash{}
+				}
+			}
+		})
+
+		It("fails", func() {
+			err := archive.Verify(100, 127)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Checkpoint 127 has bucket list hash"))
+		})
+	})
+
+	It("fails when a bucket is corrupted", func() {
+		var account xdr.AccountId
+		Expect(account.SetAddress(address)).To(Succeed())
+
+		writeFile(BucketPath(bucket), framed(xdr.BucketEntry{
+			Type: xdr.BucketEntryTypeDeadentry,
+			DeadEntry: &xdr.LedgerKey{
+				Type:    xdr.LedgerEntryTypeAccount,
+				Account: &xdr.LedgerKeyAccount{AccountId: account},
+			},
+		}))
+
+		err := archive.Verify(1, 63)
+		Expect(err).To(MatchError(ContainSubstring("Bucket " + bucket + " has hash")))
+	})
+
+	It("fails when a ledger is repeated", func() {
+		results, err := archive.GetResults(63)
+		Expect(err).NotTo(HaveOccurred())
+		writeFile(CheckpointPath("results", 63), framed(results[0], results[0]))
+		Expect(archive.Verify(1, 63)).To(MatchError("Checkpoint 63 has duplicate results of ledger 5"))
+
+		transactions, err := archive.GetTransactions(127)
+		Expect(err).NotTo(HaveOccurred())
+		writeFile(CheckpointPath("transactions", 127), framed(transactions[0], transactions[0]))
+		Expect(archive.Verify(64, 127)).To(MatchError("Checkpoint 127 has duplicate transactions of ledger 70"))
+	})
+
+	It("fails when files are missing", func() {
+		Expect(os.Remove(filepath.Join(root, filepath.FromSlash(CheckpointPath("results", 127))))).To(Succeed())
+		Expect(archive.Verify(1, 127)).NotTo(Succeed())
+	})
+
+	It("rejects unsupported archives", func() {
+		_, err := Open("http://history.example.com")
+		Expect(err).To(HaveOccurred())
+
+		_, err = Open(filepath.Join(root, "missing"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,192 @@
+package historyarchive
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"bitbucket.org/atticlab/go-smart-base/hash"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// Verify checks the integrity of the checkpoints of the archive containing
+// ledgers from to to, returning the first problem found.  It checks that:
+//
+//   - the hash of every ledger header matches its recorded hash and the
+//     previous ledger hash of the next header, across checkpoints
+//   - the transaction result set of every ledger matches the TxSetResultHash
+//     of its header, ledgers without results having an empty result set
+//   - transaction sets refer to the previous ledger of their header
+//   - results and transactions files hold each ledger at most once
+//   - the bucket list of every checkpoint matches the BucketListHash of its
+//     last header, and every bucket matches its hash
+func (a *Archive) Verify(from, to uint32) error {
+	if from > to {
+		return fmt.Errorf("Invalid ledger range: %d to %d", from, to)
+	}
+
+	var prev *xdr.Hash
+	verified := map[string]bool{}
+
+	for checkpoint := CheckpointContaining(from); checkpoint <= CheckpointContaining(to); checkpoint += CheckpointFrequency {
+		last, has, err := a.verifyCheckpoint(checkpoint, prev)
+		if err != nil {
+			return err
+		}
+		prev = &last
+
+		for _, bucket := range has.Buckets() {
+			if verified[bucket] {
+				continue
+			}
+			err = a.VerifyBucket(bucket)
+			if err != nil {
+				return err
+			}
+			verified[bucket] = true
+		}
+	}
+
+	return nil
+}
+
+// verifyCheckpoint verifies the files of a checkpoint, given the hash of the
+// last ledger of the previous checkpoint if known, and returns the hash of its
+// last ledger and its history archive state
+func (a *Archive) verifyCheckpoint(
+	checkpoint uint32,
+	prev *xdr.Hash,
+) (last xdr.Hash, has HistoryArchiveState, err error) {
+
+	headers, err := a.GetLedgerHeaders(checkpoint)
+	if err != nil {
+		return
+	}
+
+	first := checkpoint + 1 - CheckpointFrequency
+	if checkpoint < CheckpointFrequency {
+		first = 1
+	}
+	if len(headers) != int(checkpoint-first+1) {
+		err = fmt.Errorf("Checkpoint %d has %d ledger headers, expected %d", checkpoint, len(headers), checkpoint-first+1)
+		return
+	}
+
+	results, err := a.GetResults(checkpoint)
+	if err != nil {
+		return
+	}
+	resultSets := map[uint32]xdr.TransactionResultSet{}
+	for _, entry := range results {
+		seq := uint32(entry.LedgerSeq)
+		if _, ok := resultSets[seq]; ok {
+			err = fmt.Errorf("Checkpoint %d has duplicate results of ledger %d", checkpoint, seq)
+			return
+		}
+		resultSets[seq] = entry.TxResultSet
+	}
+
+	transactions, err := a.GetTransactions(checkpoint)
+	if err != nil {
+		return
+	}
+	txSets := map[uint32]xdr.TransactionSet{}
+	for _, entry := range transactions {
+		seq := uint32(entry.LedgerSeq)
+		if _, ok := txSets[seq]; ok {
+			err = fmt.Errorf("Checkpoint %d has duplicate transactions of ledger %d", checkpoint, seq)
+			return
+		}
+		txSets[seq] = entry.TxSet
+	}
+
+	for i, entry := range headers {
+		seq := first + uint32(i)
+		header := entry.Header
+		if uint32(header.LedgerSeq) != seq {
+			err = fmt.Errorf("Ledger %d found in place of ledger %d", header.LedgerSeq, seq)
+			return
+		}
+
+		var h xdr.Hash
+		h, err = hashOf(header)
+		if err != nil {
+			return
+		}
+		if h != entry.Hash {
+			err = fmt.Errorf("Ledger %d has hash %s, recorded as %s", seq, hexHash(h), hexHash(entry.Hash))
+			return
+		}
+
+		if prev != nil && header.PreviousLedgerHash != *prev {
+			err = fmt.Errorf("Ledger %d does not follow ledger %d: previous ledger hash %s, expected %s",
+				seq, seq-1, hexHash(header.PreviousLedgerHash), hexHash(*prev))
+			return
+		}
+
+		var resultHash xdr.Hash
+		resultHash, err = hashOf(resultSets[seq])
+		if err != nil {
+			return
+		}
+		if resultHash != header.TxSetResultHash {
+			err = fmt.Errorf("Ledger %d has result set hash %s, expected %s", seq, hexHash(resultHash), hexHash(header.TxSetResultHash))
+			return
+		}
+
+		if txSet, ok := txSets[seq]; ok && txSet.PreviousLedgerHash != header.PreviousLedgerHash {
+			err = fmt.Errorf("Transaction set of ledger %d does not follow ledger %d", seq, seq-1)
+			return
+		}
+
+		last = h
+		prev = &last
+	}
+
+	for seq := range resultSets {
+		if seq < first || seq > checkpoint {
+			err = fmt.Errorf("Checkpoint %d has results of ledger %d", checkpoint, seq)
+			return
+		}
+	}
+	for seq := range txSets {
+		if seq < first || seq > checkpoint {
+			err = fmt.Errorf("Checkpoint %d has transactions of ledger %d", checkpoint, seq)
+			return
+		}
+	}
+
+	has, err = a.GetCheckpointHAS(checkpoint)
+	if err != nil {
+		return
+	}
+	if has.CurrentLedger != checkpoint {
+		err = fmt.Errorf("History archive state of checkpoint %d is at ledger %d", checkpoint, has.CurrentLedger)
+		return
+	}
+
+	bucketListHash, err := has.BucketListHash()
+	if err != nil {
+		return
+	}
+	if expected := headers[len(headers)-1].Header.BucketListHash; bucketListHash != expected {
+		err = fmt.Errorf("Checkpoint %d has bucket list hash %s, expected %s", checkpoint, hexHash(bucketListHash), hexHash(expected))
+		return
+	}
+
+	return
+}
+
+// hashOf returns the hash of the xdr encoding of v
+func hashOf(v interface{}) (xdr.Hash, error) {
+	var raw bytes.Buffer
+	_, err := xdr.Marshal(&raw, v)
+	if err != nil {
+		return xdr.Hash{}, err
+	}
+	return xdr.Hash(hash.Hash(raw.Bytes())), nil
+}
+
+func hexHash(h xdr.Hash) string {
+	return hex.EncodeToString(h[:])
+}
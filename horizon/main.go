@@ -1,12 +1,17 @@
 package horizon
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 
+	"bitbucket.org/atticlab/go-smart-base/build"
 	"bitbucket.org/atticlab/go-smart-base/xdr"
 )
 
@@ -22,8 +27,115 @@ type Error struct {
 	Problem  Problem
 }
 
+// Error returns a description of the problem, including the result codes of
+// failed transactions.
 func (herror *Error) Error() string {
-	return "Horizon error"
+	msg := "Horizon error"
+	if herror.Problem.Title != "" {
+		msg += ": " + herror.Problem.Title
+	}
+
+	codes, err := herror.ResultCodes()
+	if err == nil && codes.Transaction != "" {
+		msg += " (" + codes.Transaction
+		if len(codes.Operations) > 0 {
+			msg += ", operations: " + strings.Join(codes.Operations, ", ")
+		}
+		msg += ")"
+	}
+
+	return msg
+}
+
+// TransactionResultCodes contains the result codes of a failed transaction,
+// as reported by horizon in the problem's extras.
+type TransactionResultCodes struct {
+	Transaction string   `json:"transaction"`
+	Operations  []string `json:"operations,omitempty"`
+}
+
+// ResultCodes returns the result codes of a failed transaction.
+func (herror *Error) ResultCodes() (codes TransactionResultCodes, err error) {
+	raw, ok := herror.Problem.Extras["result_codes"]
+	if !ok {
+		err = errors.New("Problem has no result codes")
+		return
+	}
+
+	// the extras were decoded generically, so re-encode them to decode them
+	// into their type
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &codes)
+	return
+}
+
+// EnvelopeXDR returns the base64-encoded envelope of a failed transaction.
+func (herror *Error) EnvelopeXDR() (string, error) {
+	return herror.extrasString("envelope_xdr")
+}
+
+// ResultXDR returns the base64-encoded result of a failed transaction.
+func (herror *Error) ResultXDR() (string, error) {
+	return herror.extrasString("result_xdr")
+}
+
+// TransactionResult decodes the result of a failed transaction.
+func (herror *Error) TransactionResult() (result xdr.TransactionResult, err error) {
+	data, err := herror.ResultXDR()
+	if err != nil {
+		return
+	}
+
+	err = xdr.SafeUnmarshalBase64(data, &result)
+	return
+}
+
+// TransactionCode returns the result code of a failed transaction.
+func (herror *Error) TransactionCode() (xdr.TransactionResultCode, error) {
+	result, err := herror.TransactionResult()
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Result.Code, nil
+}
+
+// OperationCodes returns the result codes of the operations of a failed
+// transaction, as returned by xdr.OperationResult's ResultCode, so that they
+// can be compared to constants such as
+// xdr.PaymentReversalResultCodePaymentReversalAlreadyReversed.  It returns no
+// codes when the transaction failed before its operations were applied.
+func (herror *Error) OperationCodes() ([]fmt.Stringer, error) {
+	result, err := herror.TransactionResult()
+	if err != nil {
+		return nil, err
+	}
+
+	results, _ := result.OperationResults()
+	codes := make([]fmt.Stringer, len(results))
+	for i := range results {
+		codes[i] = results[i].ResultCode()
+	}
+
+	return codes, nil
+}
+
+func (herror *Error) extrasString(key string) (string, error) {
+	raw, ok := herror.Problem.Extras[key]
+	if !ok {
+		return "", fmt.Errorf("Problem has no %s", key)
+	}
+
+	value, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("Problem has invalid %s", key)
+	}
+
+	return value, nil
 }
 
 type HorizonHttpClient interface {
@@ -36,21 +148,240 @@ type Client struct {
 	// URL of Horizon server to connect
 	URL string
 	// Will be populated with &http.Client when nil. If you want to configure your http.Client make sure Timeout is at least 10 seconds.
+	// Contexts are only honored by clients that also implement Do, as *http.Client does.
 	Client HorizonHttpClient
+	// Retry decides whether failed requests are retried.  Requests are not retried when nil.
+	Retry RetryPolicy
+	// Hooks are called around every request sent to horizon, including retries.
+	Hooks Hooks
 	// clientInit initializes http client once
 	clientInit sync.Once
 }
 
+// PathRequest contains the parameters of a path search: the paths found let
+// SourceAccount send DestinationAmount of DestinationAsset to
+// DestinationAccount.
+type PathRequest struct {
+	SourceAccount      string
+	DestinationAccount string
+	DestinationAsset   build.Asset
+	DestinationAmount  string
+}
+
+// BaseFee implements build.BaseFeeProvider. It returns the base fee of the
+// latest ledger known to horizon, as found in its header XDR once verified
+// against the ledger hash. err can be either error object or horizon.Error
+// object.
+func (c *Client) BaseFee() (uint32, error) {
+	return c.BaseFeeContext(context.Background())
+}
+
+// BaseFeeContext is BaseFee using the provided context.
+func (c *Client) BaseFeeContext(ctx context.Context) (uint32, error) {
+	ledger, err := c.LoadLatestLedgerContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	header, err := ledger.Header()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(header.BaseFee), nil
+}
+
+// FindPaths finds the payment paths allowing the source account to send the
+// destination amount to the destination account. err can be either error
+// object or horizon.Error object.
+func (c *Client) FindPaths(request PathRequest) (paths PathsPage, err error) {
+	return c.FindPathsContext(context.Background(), request)
+}
+
+// FindPathsContext is FindPaths using the provided context.
+func (c *Client) FindPathsContext(ctx context.Context, request PathRequest) (paths PathsPage, err error) {
+	v := url.Values{}
+	v.Set("source_account", request.SourceAccount)
+	v.Set("destination_account", request.DestinationAccount)
+	v.Set("destination_amount", request.DestinationAmount)
+	assetQuery(v, "destination_", request.DestinationAsset)
+
+	err = c.load(ctx, "/paths?"+v.Encode(), &paths)
+	return
+}
+
 // LoadAccount loads the account state from horizon. err can be either error
 // object or horizon.Error object.
 func (c *Client) LoadAccount(accountID string) (account Account, err error) {
-	c.initHttpClient()
-	resp, err := c.Client.Get(c.URL + "/accounts/" + accountID)
+	return c.LoadAccountContext(context.Background(), accountID)
+}
+
+// LoadAccountContext is LoadAccount using the provided context.
+func (c *Client) LoadAccountContext(ctx context.Context, accountID string) (account Account, err error) {
+	err = c.load(ctx, "/accounts/"+accountID, &account)
+	return
+}
+
+// LoadEffects loads the first page of effects, using the provided paging
+// parameters.  When accountID is not empty only the effects of that account
+// are loaded. err can be either error object or horizon.Error object.
+func (c *Client) LoadEffects(accountID string, page PageRequest) (effects EffectsPage, err error) {
+	return c.LoadEffectsContext(context.Background(), accountID, page)
+}
+
+// LoadEffectsContext is LoadEffects using the provided context.
+func (c *Client) LoadEffectsContext(ctx context.Context, accountID string, page PageRequest) (effects EffectsPage, err error) {
+	err = c.load(ctx, accountPath(accountID, "/effects")+page.query(), &effects)
+	return
+}
+
+// LoadOperation loads a single operation from horizon. err can be either
+// error object or horizon.Error object.
+func (c *Client) LoadOperation(operationID string) (operation Operation, err error) {
+	return c.LoadOperationContext(context.Background(), operationID)
+}
+
+// LoadOperationContext is LoadOperation using the provided context.
+func (c *Client) LoadOperationContext(ctx context.Context, operationID string) (operation Operation, err error) {
+	err = c.load(ctx, "/operations/"+operationID, &operation)
+	return
+}
+
+// LoadOperations loads the first page of operations, using the provided
+// paging parameters.  When accountID is not empty only the operations of that
+// account are loaded. err can be either error object or horizon.Error object.
+func (c *Client) LoadOperations(accountID string, page PageRequest) (operations OperationsPage, err error) {
+	return c.LoadOperationsContext(context.Background(), accountID, page)
+}
+
+// LoadOperationsContext is LoadOperations using the provided context.
+func (c *Client) LoadOperationsContext(ctx context.Context, accountID string, page PageRequest) (operations OperationsPage, err error) {
+	err = c.load(ctx, accountPath(accountID, "/operations")+page.query(), &operations)
+	return
+}
+
+// LoadLatestLedger loads the last ledger closed by the network. Use
+// Ledger.Header() to read its verified base fee and base reserve. err can be
+// either error object or horizon.Error object.
+func (c *Client) LoadLatestLedger() (ledger Ledger, err error) {
+	return c.LoadLatestLedgerContext(context.Background())
+}
+
+// LoadLatestLedgerContext is LoadLatestLedger using the provided context.
+func (c *Client) LoadLatestLedgerContext(ctx context.Context) (ledger Ledger, err error) {
+	page, err := c.LoadLedgersContext(ctx, PageRequest{Order: OrderDesc, Limit: 1})
 	if err != nil {
 		return
 	}
 
-	err = decodeResponse(resp, &account)
+	if len(page.Embedded.Records) == 0 {
+		err = errors.New("No ledgers found")
+		return
+	}
+
+	ledger = page.Embedded.Records[0]
+	return
+}
+
+// LoadLedger loads a single ledger from horizon. err can be either error
+// object or horizon.Error object.
+func (c *Client) LoadLedger(sequence int32) (ledger Ledger, err error) {
+	return c.LoadLedgerContext(context.Background(), sequence)
+}
+
+// LoadLedgerContext is LoadLedger using the provided context.
+func (c *Client) LoadLedgerContext(ctx context.Context, sequence int32) (ledger Ledger, err error) {
+	err = c.load(ctx, "/ledgers/"+strconv.FormatInt(int64(sequence), 10), &ledger)
+	return
+}
+
+// LoadLedgers loads the first page of ledgers, using the provided paging
+// parameters. err can be either error object or horizon.Error object.
+func (c *Client) LoadLedgers(page PageRequest) (ledgers LedgersPage, err error) {
+	return c.LoadLedgersContext(context.Background(), page)
+}
+
+// LoadLedgersContext is LoadLedgers using the provided context.
+func (c *Client) LoadLedgersContext(ctx context.Context, page PageRequest) (ledgers LedgersPage, err error) {
+	err = c.load(ctx, "/ledgers"+page.query(), &ledgers)
+	return
+}
+
+// LoadOffers loads the first page of the offers of the account, using the
+// provided paging parameters. err can be either error object or
+// horizon.Error object.
+func (c *Client) LoadOffers(accountID string, page PageRequest) (offers OffersPage, err error) {
+	return c.LoadOffersContext(context.Background(), accountID, page)
+}
+
+// LoadOffersContext is LoadOffers using the provided context.
+func (c *Client) LoadOffersContext(ctx context.Context, accountID string, page PageRequest) (offers OffersPage, err error) {
+	err = c.load(ctx, "/accounts/"+accountID+"/offers"+page.query(), &offers)
+	return
+}
+
+// LoadOrderBook loads the bids and asks of the order book selling the
+// selling asset for the buying asset.  A zero limit lets horizon use its
+// default. err can be either error object or horizon.Error object.
+func (c *Client) LoadOrderBook(selling build.Asset, buying build.Asset, limit uint) (orderBook OrderBookSummary, err error) {
+	return c.LoadOrderBookContext(context.Background(), selling, buying, limit)
+}
+
+// LoadOrderBookContext is LoadOrderBook using the provided context.
+func (c *Client) LoadOrderBookContext(
+	ctx context.Context,
+	selling build.Asset,
+	buying build.Asset,
+	limit uint,
+) (orderBook OrderBookSummary, err error) {
+
+	v := url.Values{}
+	assetQuery(v, "selling_", selling)
+	assetQuery(v, "buying_", buying)
+	if limit != 0 {
+		v.Set("limit", strconv.FormatUint(uint64(limit), 10))
+	}
+
+	err = c.load(ctx, "/order_book?"+v.Encode(), &orderBook)
+	return
+}
+
+// LoadPayments loads the first page of payments, using the provided paging
+// parameters.  When accountID is not empty only the payments of that account
+// are loaded. err can be either error object or horizon.Error object.
+func (c *Client) LoadPayments(accountID string, page PageRequest) (payments PaymentsPage, err error) {
+	return c.LoadPaymentsContext(context.Background(), accountID, page)
+}
+
+// LoadPaymentsContext is LoadPayments using the provided context.
+func (c *Client) LoadPaymentsContext(ctx context.Context, accountID string, page PageRequest) (payments PaymentsPage, err error) {
+	err = c.load(ctx, accountPath(accountID, "/payments")+page.query(), &payments)
+	return
+}
+
+// LoadTransaction loads a single transaction from horizon. err can be either
+// error object or horizon.Error object.
+func (c *Client) LoadTransaction(transactionHash string) (transaction Transaction, err error) {
+	return c.LoadTransactionContext(context.Background(), transactionHash)
+}
+
+// LoadTransactionContext is LoadTransaction using the provided context.
+func (c *Client) LoadTransactionContext(ctx context.Context, transactionHash string) (transaction Transaction, err error) {
+	err = c.load(ctx, "/transactions/"+transactionHash, &transaction)
+	return
+}
+
+// LoadTransactions loads the first page of transactions, using the provided
+// paging parameters.  When accountID is not empty only the transactions of
+// that account are loaded. err can be either error object or horizon.Error
+// object.
+func (c *Client) LoadTransactions(accountID string, page PageRequest) (transactions TransactionsPage, err error) {
+	return c.LoadTransactionsContext(context.Background(), accountID, page)
+}
+
+// LoadTransactionsContext is LoadTransactions using the provided context.
+func (c *Client) LoadTransactionsContext(ctx context.Context, accountID string, page PageRequest) (transactions TransactionsPage, err error) {
+	err = c.load(ctx, accountPath(accountID, "/transactions")+page.query(), &transactions)
 	return
 }
 
@@ -58,8 +389,16 @@ func (c *Client) LoadAccount(accountID string) (account Account, err error) {
 func (c *Client) SequenceForAccount(
 	accountID string,
 ) (xdr.SequenceNumber, error) {
+	return c.SequenceForAccountContext(context.Background(), accountID)
+}
+
+// SequenceForAccountContext is SequenceForAccount using the provided context.
+func (c *Client) SequenceForAccountContext(
+	ctx context.Context,
+	accountID string,
+) (xdr.SequenceNumber, error) {
 
-	a, err := c.LoadAccount(accountID)
+	a, err := c.LoadAccountContext(ctx, accountID)
 	if err != nil {
 		return 0, err
 	}
@@ -74,11 +413,26 @@ func (c *Client) SequenceForAccount(
 
 // SubmitTransaction submits a transaction to the network. err can be either error object or horizon.Error object.
 func (c *Client) SubmitTransaction(transactionEnvelopeXdr string) (response TransactionSuccess, err error) {
+	return c.SubmitTransactionContext(context.Background(), transactionEnvelopeXdr)
+}
+
+// SubmitTransactionContext is SubmitTransaction using the provided context.
+// When the client has a Retry policy, failed submissions are retried by
+// resubmitting the very same envelope: horizon answers a resubmission of an
+// already applied transaction with its original result, so a transaction is
+// never applied twice.
+func (c *Client) SubmitTransactionContext(ctx context.Context, transactionEnvelopeXdr string) (response TransactionSuccess, err error) {
 	v := url.Values{}
 	v.Set("tx", transactionEnvelopeXdr)
 
-	c.initHttpClient()
-	resp, err := c.Client.PostForm(c.URL+"/transactions", v)
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", c.URL+"/transactions", strings.NewReader(v.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return req, nil
+	})
 	if err != nil {
 		return
 	}
@@ -87,6 +441,49 @@ func (c *Client) SubmitTransaction(transactionEnvelopeXdr string) (response Tran
 	return
 }
 
+// load GETs the provided path and decodes the response into object
+func (c *Client) load(ctx context.Context, path string, object interface{}) error {
+	return c.loadURL(ctx, c.URL+path, object)
+}
+
+// loadURL GETs the provided url and decodes the response into object
+func (c *Client) loadURL(ctx context.Context, u string, object interface{}) error {
+	resp, err := c.do(ctx, func() (*http.Request, error) {
+		return http.NewRequest("GET", u, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	return decodeResponse(resp, object)
+}
+
+// accountPath returns the path of a collection, scoped to an account when
+// accountID is not empty
+func accountPath(accountID string, collection string) string {
+	if accountID == "" {
+		return collection
+	}
+	return "/accounts/" + accountID + collection
+}
+
+// assetQuery adds the query parameters describing the asset, prefixed with
+// prefix, to v
+func assetQuery(v url.Values, prefix string, asset build.Asset) {
+	if asset.Native {
+		v.Set(prefix+"asset_type", "native")
+		return
+	}
+
+	assetType := "credit_alphanum4"
+	if len(asset.Code) > 4 {
+		assetType = "credit_alphanum12"
+	}
+	v.Set(prefix+"asset_type", assetType)
+	v.Set(prefix+"asset_code", asset.Code)
+	v.Set(prefix+"asset_issuer", asset.Issuer)
+}
+
 func (c *Client) initHttpClient() {
 	c.clientInit.Do(func() {
 		if c.Client == nil {
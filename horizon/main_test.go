@@ -6,11 +6,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
 )
 
 func TestHorizon(t *testing.T) {
@@ -19,6 +21,7 @@ func TestHorizon(t *testing.T) {
 }
 
 var _ build.SequenceProvider = TestHorizonClient
+var _ build.BaseFeeProvider = TestHorizonClient
 
 var _ = Describe("Horizon", func() {
 	Describe("initHttpClient", func() {
@@ -59,7 +62,7 @@ var _ = Describe("Horizon", func() {
 
 			_, err := TestHorizonClient.LoadAccount("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
 			Expect(err).NotTo(BeNil())
-			Expect(err.Error()).To(Equal("Horizon error"))
+			Expect(err.Error()).To(Equal("Horizon error: Resource Missing"))
 			horizonError, ok := err.(*Error)
 			Expect(ok).To(BeTrue())
 			Expect(horizonError.Problem.Title).To(Equal("Resource Missing"))
@@ -78,6 +81,319 @@ var _ = Describe("Horizon", func() {
 		})
 	})
 
+	Describe("BaseFee", func() {
+		It("success response", func() {
+			TestHorizonClient.Client = &TestHttpClient{
+				Response: http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(ledgersResponse)),
+				},
+			}
+
+			fee, err := TestHorizonClient.BaseFee()
+			Expect(err).To(BeNil())
+			Expect(fee).To(Equal(uint32(100)))
+		})
+
+		It("uses the base fee of the verified header", func() {
+			response := strings.Replace(ledgersResponse, `"base_fee": 100`, `"base_fee": 5000`, 1)
+			TestHorizonClient.Client = &TestHttpClient{
+				Response: http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(response)),
+				},
+			}
+
+			fee, err := TestHorizonClient.BaseFee()
+			Expect(err).To(BeNil())
+			Expect(fee).To(Equal(uint32(100)))
+		})
+
+		It("header not matching the ledger hash", func() {
+			response := strings.Replace(ledgersResponse,
+				`"hash": "bd51afd27af0575c4b8f77ecb35db2c36cb3aeef5780a8486747fb6a48f845aa"`,
+				`"hash": "0000000000000000000000000000000000000000000000000000000000000000"`, 1)
+			TestHorizonClient.Client = &TestHttpClient{
+				Response: http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(response)),
+				},
+			}
+
+			_, err := TestHorizonClient.BaseFee()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(ContainSubstring("does not match ledger hash"))
+		})
+
+		It("empty response", func() {
+			TestHorizonClient.Client = &TestHttpClient{
+				Response: http.Response{
+					StatusCode: 200,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{"_embedded": {"records": []}}`)),
+				},
+			}
+
+			_, err := TestHorizonClient.BaseFee()
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("connection error", func() {
+			TestHorizonClient.Client = &TestHttpClient{
+				Error: errors.New("http.Client error"),
+			}
+
+			_, err := TestHorizonClient.BaseFee()
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(Equal("http.Client error"))
+		})
+	})
+
+	Describe("LoadTransactions", func() {
+		It("requests the account's transactions", func() {
+			client := &RoutedHttpClient{Responses: map[string]string{
+				"/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/transactions?limit=1&order=asc": transactionsPage1,
+			}}
+			TestHorizonClient.Client = client
+
+			page, err := TestHorizonClient.LoadTransactions(
+				"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+				PageRequest{Order: OrderAsc, Limit: 1},
+			)
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+			Expect(page.Embedded.Records[0].Hash).To(Equal("ee14b93fcd31d4cfe835b941a0a8744e23a6677097db1fafe0552d8657bed940"))
+			Expect(page.Embedded.Records[0].OperationCount).To(Equal(int32(1)))
+		})
+
+		It("failure response", func() {
+			TestHorizonClient.Client = &TestHttpClient{
+				Response: http.Response{
+					StatusCode: 404,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(notFoundResponse)),
+				},
+			}
+
+			_, err := TestHorizonClient.LoadTransactions("", PageRequest{})
+			_, ok := err.(*Error)
+			Expect(ok).To(BeTrue())
+		})
+	})
+
+	Describe("LoadPayments", func() {
+		It("decodes payment records", func() {
+			TestHorizonClient.Client = &RoutedHttpClient{Responses: map[string]string{
+				"/payments": paymentsPage,
+			}}
+
+			page, err := TestHorizonClient.LoadPayments("", PageRequest{})
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+			payment := page.Embedded.Records[0]
+			Expect(payment.Type).To(Equal("payment"))
+			Expect(payment.Amount).To(Equal("10.0000000"))
+			Expect(payment.AssetCode).To(Equal("USD"))
+			Expect(payment.Asset()).To(Equal(Asset{
+				Type:   "credit_alphanum4",
+				Code:   "USD",
+				Issuer: "GA2IYMIZSAMDD6QQTTSIEL73H2BKDJQTA7ENDEEAHJ3LMVF7OYIZPXQD",
+			}))
+		})
+	})
+
+	Describe("LoadLatestLedger", func() {
+		BeforeEach(func() {
+			TestHorizonClient.Client = &RoutedHttpClient{Responses: map[string]string{
+				"/ledgers?limit=1&order=desc": ledgersResponse,
+			}}
+		})
+
+		It("decodes the verified ledger header", func() {
+			ledger, err := TestHorizonClient.LoadLatestLedger()
+			Expect(err).To(BeNil())
+			Expect(ledger.Sequence).To(Equal(int32(3128912)))
+
+			header, err := ledger.Header()
+			Expect(err).To(BeNil())
+			Expect(header.LedgerSeq).To(Equal(xdr.Uint32(3128912)))
+			Expect(header.BaseFee).To(Equal(xdr.Uint32(100)))
+			Expect(header.BaseReserve).To(Equal(xdr.Uint32(100000000)))
+		})
+
+		It("rejects headers not matching the ledger hash", func() {
+			ledger, err := TestHorizonClient.LoadLatestLedger()
+			Expect(err).To(BeNil())
+
+			ledger.Hash = "4b0e5ba1e2e1c7e0a0d2b6f1d6a0c9b8a7f6e5d4c3b2a1908070605040302010"
+			_, err = ledger.Header()
+			Expect(err).To(MatchError(ContainSubstring("does not match ledger hash")))
+		})
+	})
+
+	Describe("LoadOffers", func() {
+		It("decodes offers into rates", func() {
+			TestHorizonClient.Client = &RoutedHttpClient{Responses: map[string]string{
+				"/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/offers?limit=10": offersPage,
+			}}
+
+			page, err := TestHorizonClient.LoadOffers("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", PageRequest{Limit: 10})
+			Expect(err).To(BeNil())
+			Expect(page.Embedded.Records).To(HaveLen(1))
+
+			offer := page.Embedded.Records[0]
+			Expect(offer.OfferID()).To(Equal(build.OfferID(121)))
+			Expect(offer.PriceR).To(Equal(Price{N: 1, D: 2}))
+			Expect(offer.Rate()).To(Equal(build.Rate{
+				Selling: build.NativeAsset(),
+				Buying:  build.CreditAsset("USD", "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"),
+				Price:   build.Price("0.5000000"),
+			}))
+
+			op := build.UpdateOffer(offer.Rate(), build.Amount("10"), offer.OfferID())
+			Expect(op.Err).To(BeNil())
+		})
+	})
+
+	Describe("LoadOrderBook", func() {
+		It("queries the asset pair", func() {
+			TestHorizonClient.Client = &RoutedHttpClient{Responses: map[string]string{
+				"/order_book?buying_asset_code=USD&buying_asset_issuer=GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ&buying_asset_type=credit_alphanum4&limit=5&selling_asset_type=native": orderBookResponse,
+			}}
+
+			usd := build.CreditAsset("USD", "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ")
+			book, err := TestHorizonClient.LoadOrderBook(build.NativeAsset(), usd, 5)
+			Expect(err).To(BeNil())
+			Expect(book.Bids).To(HaveLen(1))
+			Expect(book.Asks).To(HaveLen(2))
+			Expect(book.Asks[0].PriceR).To(Equal(Price{N: 21, D: 40}))
+			Expect(book.Rate(book.Asks[0].Price)).To(Equal(build.Rate{
+				Selling: build.NativeAsset(),
+				Buying:  usd,
+				Price:   build.Price("0.5250000"),
+			}))
+		})
+	})
+
+	Describe("FindPaths", func() {
+		It("decodes paths into path payments", func() {
+			TestHorizonClient.Client = &RoutedHttpClient{Responses: map[string]string{
+				"/paths?destination_account=GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H&destination_amount=20&destination_asset_code=EUR&destination_asset_issuer=GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ&destination_asset_type=credit_alphanum4&source_account=GA3FR7TVTDJAY6TN4MUX7BF4KK6SUHWIYDY7NRNUDTA4OVY3IMY7B6H5": pathsResponse,
+			}}
+
+			eur := build.CreditAsset("EUR", "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ")
+			paths, err := TestHorizonClient.FindPaths(PathRequest{
+				SourceAccount:      "GA3FR7TVTDJAY6TN4MUX7BF4KK6SUHWIYDY7NRNUDTA4OVY3IMY7B6H5",
+				DestinationAccount: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+				DestinationAsset:   eur,
+				DestinationAmount:  "20",
+			})
+			Expect(err).To(BeNil())
+			Expect(paths.Embedded.Records).To(HaveLen(1))
+
+			path := paths.Embedded.Records[0]
+			Expect(path.SourceAmount).To(Equal("30.0000000"))
+			Expect(path.DestinationAsset().BuildAsset()).To(Equal(eur))
+			Expect(path.PayWith("31")).To(Equal(
+				build.PayWith(build.NativeAsset(), "31").
+					Through(build.CreditAsset("USD", "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ")),
+			))
+		})
+	})
+
+	Describe("Iterate", func() {
+		It("walks every page", func() {
+			TestHorizonClient.Client = &RoutedHttpClient{Responses: map[string]string{
+				"/transactions?limit=1": transactionsPage1,
+				"https://horizon-testnet.stellar.org/transactions?cursor=2&limit=1&order=asc": transactionsPage2,
+				"https://horizon-testnet.stellar.org/transactions?cursor=3&limit=1&order=asc": transactionsPageEmpty,
+			}}
+
+			page, err := TestHorizonClient.LoadTransactions("", PageRequest{Limit: 1})
+			Expect(err).To(BeNil())
+
+			var hashes []string
+			it := TestHorizonClient.Iterate(&page)
+			for it.Next() {
+				for _, tx := range page.Embedded.Records {
+					hashes = append(hashes, tx.Hash)
+				}
+			}
+
+			Expect(it.Err()).To(BeNil())
+			Expect(hashes).To(Equal([]string{
+				"ee14b93fcd31d4cfe835b941a0a8744e23a6677097db1fafe0552d8657bed940",
+				"a2dabf4e9d1642722602272e178a37c973c9177b957da86192a99b3e9f3a9aa4",
+			}))
+		})
+
+		It("stops on errors", func() {
+			TestHorizonClient.Client = &RoutedHttpClient{Responses: map[string]string{
+				"/transactions": transactionsPage1,
+			}}
+
+			page, err := TestHorizonClient.LoadTransactions("", PageRequest{})
+			Expect(err).To(BeNil())
+
+			it := TestHorizonClient.Iterate(&page)
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Next()).To(BeFalse())
+			Expect(it.Err()).NotTo(BeNil())
+		})
+	})
+
+	Describe("TransactionSuccess", func() {
+		var success TransactionSuccess
+
+		BeforeEach(func() {
+			entry := xdr.LedgerEntry{LastModifiedLedgerSeq: 3128812}
+			entry.Data, _ = xdr.NewLedgerEntryData(xdr.LedgerEntryTypeReversedPayment, xdr.ReversedPaymentEntry{Id: 42})
+			change, _ := xdr.NewLedgerEntryChange(xdr.LedgerEntryChangeTypeLedgerEntryCreated, entry)
+			txMeta, _ := xdr.NewTransactionMeta(0, []xdr.OperationMeta{
+				{Changes: xdr.LedgerEntryChanges{change}},
+			})
+
+			var err error
+			success.Meta, err = xdr.MarshalBase64(txMeta)
+			Expect(err).To(BeNil())
+			success.Result = "AAAAAAAAAAEAAAAAAAAADAAAAAAAAAAA"
+		})
+
+		It("decodes the result", func() {
+			result, err := success.TransactionResult()
+			Expect(err).To(BeNil())
+			Expect(result.Result.Code).To(Equal(xdr.TransactionResultCodeTxSuccess))
+			Expect(result.Result.MustResults()).To(HaveLen(1))
+		})
+
+		It("decodes the meta into a bundle", func() {
+			bundle, err := success.Bundle()
+			Expect(err).To(BeNil())
+
+			var key xdr.LedgerKey
+			Expect(key.SetReversedPayment(42)).To(Succeed())
+
+			reversed, err := bundle.StateAfter(key, 0)
+			Expect(err).To(BeNil())
+			Expect(reversed.Data.MustReversedPayment().Id).To(Equal(xdr.Int64(42)))
+			Expect(bundle.Created()).To(HaveLen(1))
+		})
+
+		It("decodes the envelope", func() {
+			b := build.TransactionEnvelopeBuilder{}
+			b.MutateTX(
+				build.SourceAccount{AddressOrSeed: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"},
+				build.Sequence{Sequence: 7},
+			)
+
+			var err error
+			success.Env, err = b.Base64()
+			Expect(err).To(BeNil())
+
+			env, err := success.TransactionEnvelope()
+			Expect(err).To(BeNil())
+			Expect(env.Tx.SeqNum).To(Equal(xdr.SequenceNumber(7)))
+		})
+	})
+
 	Describe("SubmitTransaction", func() {
 		var tx = "AAAAADSMMRmQGDH6EJzkgi/7PoKhphMHyNGQgDp2tlS/dhGXAAAAZAAT3TUAAAAwAAAAAAAAAAAAAAABAAAAAAAAAAMAAAABSU5SAAAAAAA0jDEZkBgx+hCc5IIv+z6CoaYTB8jRkIA6drZUv3YRlwAAAAFVU0QAAAAAADSMMRmQGDH6EJzkgi/7PoKhphMHyNGQgDp2tlS/dhGXAAAAAAX14QAAAAAKAAAAAQAAAAAAAAAAAAAAAAAAAAG/dhGXAAAAQLuStfImg0OeeGAQmvLkJSZ1MPSkCzCYNbGqX5oYNuuOqZ5SmWhEsC7uOD9ha4V7KengiwNlc0oMNqBVo22S7gk="
 
@@ -104,12 +420,35 @@ var _ = Describe("Horizon", func() {
 
 			_, err := TestHorizonClient.SubmitTransaction(tx)
 			Expect(err).NotTo(BeNil())
-			Expect(err.Error()).To(Equal("Horizon error"))
+			Expect(err.Error()).To(Equal("Horizon error: Transaction Failed (tx_no_source_account)"))
 			horizonError, ok := err.(*Error)
 			Expect(ok).To(BeTrue())
 			Expect(horizonError.Problem.Title).To(Equal("Transaction Failed"))
 		})
 
+		It("failure response with operation codes", func() {
+			TestHorizonClient.Client = &TestHttpClient{
+				Response: http.Response{
+					StatusCode: 400,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(reversalFailure)),
+				},
+			}
+
+			_, err := TestHorizonClient.SubmitTransaction(tx)
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(Equal("Horizon error: Transaction Failed (tx_failed, operations: op_already_reversed)"))
+
+			horizonError := err.(*Error)
+			code, err := horizonError.TransactionCode()
+			Expect(err).To(BeNil())
+			Expect(code).To(Equal(xdr.TransactionResultCodeTxFailed))
+
+			codes, err := horizonError.OperationCodes()
+			Expect(err).To(BeNil())
+			Expect(codes).To(HaveLen(1))
+			Expect(codes[0]).To(Equal(xdr.PaymentReversalResultCodePaymentReversalAlreadyReversed))
+		})
+
 		It("connection error", func() {
 			TestHorizonClient.Client = &TestHttpClient{
 				Error: errors.New("http.Client error"),
@@ -141,6 +480,108 @@ func (tc *TestHttpClient) PostForm(url string, data url.Values) (resp *http.Resp
 	return &tc.Response, tc.Error
 }
 
+// RoutedHttpClient responds to GET requests with the body registered for the
+// requested url.
+type RoutedHttpClient struct {
+	Responses map[string]string
+}
+
+func (rc *RoutedHttpClient) Get(url string) (*http.Response, error) {
+	body, ok := rc.Responses[url]
+	if !ok {
+		return nil, errors.New("unexpected request: " + url)
+	}
+
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(body)),
+	}, nil
+}
+
+func (rc *RoutedHttpClient) PostForm(url string, data url.Values) (*http.Response, error) {
+	return nil, errors.New("unexpected request: " + url)
+}
+
+var transactionsPage1 = `{
+  "_links": {
+    "self": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=&limit=1&order=asc"},
+    "next": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=2&limit=1&order=asc"},
+    "prev": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=1&limit=1&order=desc"}
+  },
+  "_embedded": {
+    "records": [
+      {
+        "id": "ee14b93fcd31d4cfe835b941a0a8744e23a6677097db1fafe0552d8657bed940",
+        "paging_token": "2",
+        "hash": "ee14b93fcd31d4cfe835b941a0a8744e23a6677097db1fafe0552d8657bed940",
+        "ledger": 3128812,
+        "created_at": "2016-03-02T10:20:31Z",
+        "source_account": "GA2IYMIZSAMDD6QQTTSIEL73H2BKDJQTA7ENDEEAHJ3LMVF7OYIZPXQD",
+        "source_account_sequence": "5603886203338800",
+        "fee_paid": 100,
+        "operation_count": 1,
+        "memo_type": "none",
+        "signatures": ["u5K18iaDQ554YBCa8uQlJnUw9KQLMJg1sapfmhg2646pnlKZaESwLu44P2FrhXsp6eCLA2VzSgw2oFWjbZLuCQ=="]
+      }
+    ]
+  }
+}`
+
+var transactionsPage2 = `{
+  "_links": {
+    "self": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=2&limit=1&order=asc"},
+    "next": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=3&limit=1&order=asc"},
+    "prev": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=3&limit=1&order=desc"}
+  },
+  "_embedded": {
+    "records": [
+      {
+        "id": "a2dabf4e9d1642722602272e178a37c973c9177b957da86192a99b3e9f3a9aa4",
+        "paging_token": "3",
+        "hash": "a2dabf4e9d1642722602272e178a37c973c9177b957da86192a99b3e9f3a9aa4",
+        "ledger": 3128813,
+        "operation_count": 2
+      }
+    ]
+  }
+}`
+
+var transactionsPageEmpty = `{
+  "_links": {
+    "self": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=3&limit=1&order=asc"},
+    "next": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=3&limit=1&order=asc"},
+    "prev": {"href": "https://horizon-testnet.stellar.org/transactions?cursor=3&limit=1&order=desc"}
+  },
+  "_embedded": {
+    "records": []
+  }
+}`
+
+var paymentsPage = `{
+  "_links": {
+    "self": {"href": "https://horizon-testnet.stellar.org/payments?cursor=&limit=10&order=asc"},
+    "next": {"href": "https://horizon-testnet.stellar.org/payments?cursor=13438604496195585&limit=10&order=asc"},
+    "prev": {"href": "https://horizon-testnet.stellar.org/payments?cursor=13438604496195585&limit=10&order=desc"}
+  },
+  "_embedded": {
+    "records": [
+      {
+        "id": "13438604496195585",
+        "paging_token": "13438604496195585",
+        "source_account": "GA2IYMIZSAMDD6QQTTSIEL73H2BKDJQTA7ENDEEAHJ3LMVF7OYIZPXQD",
+        "type": "payment",
+        "type_i": 1,
+        "from": "GA2IYMIZSAMDD6QQTTSIEL73H2BKDJQTA7ENDEEAHJ3LMVF7OYIZPXQD",
+        "to": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+        "amount": "10.0000000",
+        "asset_type": "credit_alphanum4",
+        "asset_code": "USD",
+        "asset_issuer": "GA2IYMIZSAMDD6QQTTSIEL73H2BKDJQTA7ENDEEAHJ3LMVF7OYIZPXQD"
+      }
+    ]
+  }
+}`
+
 var accountResponse = `{
   "_links": {
     "self": {
@@ -195,6 +636,97 @@ var accountResponse = `{
   ]
 }`
 
+var offersPage = `{
+  "_links": {
+    "self": {"href": "/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/offers?order=asc&limit=10&cursor="},
+    "next": {"href": "/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/offers?order=asc&limit=10&cursor=121"},
+    "prev": {"href": "/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/offers?order=desc&limit=10&cursor=121"}
+  },
+  "_embedded": {
+    "records": [
+      {
+        "_links": {
+          "self": {"href": "/offers/121"},
+          "offer_maker": {"href": "/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"}
+        },
+        "id": 121,
+        "paging_token": "121",
+        "seller": "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H",
+        "selling": {"asset_type": "native"},
+        "buying": {
+          "asset_type": "credit_alphanum4",
+          "asset_code": "USD",
+          "asset_issuer": "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+        },
+        "amount": "100.0000000",
+        "price_r": {"n": 1, "d": 2},
+        "price": "0.5000000"
+      }
+    ]
+  }
+}`
+
+var orderBookResponse = `{
+  "bids": [
+    {"price_r": {"n": 1, "d": 2}, "price": "0.5000000", "amount": "50.0000000"}
+  ],
+  "asks": [
+    {"price_r": {"n": 21, "d": 40}, "price": "0.5250000", "amount": "20.0000000"},
+    {"price_r": {"n": 11, "d": 20}, "price": "0.5500000", "amount": "80.0000000"}
+  ],
+  "base": {"asset_type": "native"},
+  "counter": {
+    "asset_type": "credit_alphanum4",
+    "asset_code": "USD",
+    "asset_issuer": "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+  }
+}`
+
+var pathsResponse = `{
+  "_embedded": {
+    "records": [
+      {
+        "source_asset_type": "native",
+        "source_amount": "30.0000000",
+        "destination_asset_type": "credit_alphanum4",
+        "destination_asset_code": "EUR",
+        "destination_asset_issuer": "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ",
+        "destination_amount": "20.0000000",
+        "path": [
+          {
+            "asset_type": "credit_alphanum4",
+            "asset_code": "USD",
+            "asset_issuer": "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+          }
+        ]
+      }
+    ]
+  }
+}`
+
+var ledgersResponse = `{
+  "_embedded": {
+    "records": [
+      {
+        "id": "bd51afd27af0575c4b8f77ecb35db2c36cb3aeef5780a8486747fb6a48f845aa",
+        "paging_token": "13438604496195584",
+        "hash": "bd51afd27af0575c4b8f77ecb35db2c36cb3aeef5780a8486747fb6a48f845aa",
+        "prev_hash": "4b0e5ba1e2e1c7e0a0d2b6f1d6a0c9b8a7f6e5d4c3b2a1908070605040302010",
+        "sequence": 3128912,
+        "transaction_count": 2,
+        "operation_count": 3,
+        "closed_at": "2016-03-02T10:20:31Z",
+        "total_coins": "100000000000.0000000",
+        "fee_pool": "0.0028600",
+        "base_fee": 100,
+        "base_reserve": "10.0000000",
+        "max_tx_set_size": 500,
+        "header_xdr": "AAAAAQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAvvlAN4Lazp2QAAAAAAAAAAG+4AAAAAQAAAAAAAAB5AAAAZAX14QAAAAH0AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+      }
+    ]
+  }
+}`
+
 var notFoundResponse = `{
   "type": "https://stellar.org/horizon-errors/not_found",
   "title": "Resource Missing",
@@ -230,3 +762,16 @@ var transactionFailure = `{
     "result_xdr": "AAAAAAAAAAD////4AAAAAA=="
   }
 }`
+
+var reversalFailure = `{
+  "type": "https://stellar.org/horizon-errors/transaction_failed",
+  "title": "Transaction Failed",
+  "status": 400,
+  "extras": {
+    "result_codes": {
+      "transaction": "tx_failed",
+      "operations": ["op_already_reversed"]
+    },
+    "result_xdr": "/////wAAAAEAAAAAAAAADP///+0AAAAA"
+  }
+}`
@@ -0,0 +1,151 @@
+package horizon
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+const (
+	// OrderAsc requests records in ascending order
+	OrderAsc = "asc"
+	// OrderDesc requests records in descending order
+	OrderDesc = "desc"
+)
+
+// PageRequest contains the paging parameters used when loading the first
+// page of a collection.  Zero values are omitted, letting horizon use its
+// defaults.
+type PageRequest struct {
+	Cursor string
+	Order  string
+	Limit  uint
+}
+
+// query returns the query string for the request, including the leading `?`
+// when not empty.
+func (p PageRequest) query() string {
+	v := url.Values{}
+	if p.Cursor != "" {
+		v.Set("cursor", p.Cursor)
+	}
+	if p.Order != "" {
+		v.Set("order", p.Order)
+	}
+	if p.Limit != 0 {
+		v.Set("limit", strconv.FormatUint(uint64(p.Limit), 10))
+	}
+
+	if len(v) == 0 {
+		return ""
+	}
+	return "?" + v.Encode()
+}
+
+// PageLinks contains the HAL links of a page of records
+type PageLinks struct {
+	Self Link `json:"self"`
+	Next Link `json:"next"`
+	Prev Link `json:"prev"`
+}
+
+// Page is implemented by pointers to the pages of records returned by
+// horizon.
+type Page interface {
+	// Paging returns the links to the current, next and previous page
+	Paging() PageLinks
+	// Len returns the number of records in the page
+	Len() int
+}
+
+// NextPage replaces the provided page with the page following it.
+func (c *Client) NextPage(page Page) error {
+	return c.NextPageContext(context.Background(), page)
+}
+
+// NextPageContext is NextPage using the provided context.
+func (c *Client) NextPageContext(ctx context.Context, page Page) error {
+	return c.loadPage(ctx, page.Paging().Next.Href, page)
+}
+
+// PrevPage replaces the provided page with the page preceding it.
+func (c *Client) PrevPage(page Page) error {
+	return c.PrevPageContext(context.Background(), page)
+}
+
+// PrevPageContext is PrevPage using the provided context.
+func (c *Client) PrevPageContext(ctx context.Context, page Page) error {
+	return c.loadPage(ctx, page.Paging().Prev.Href, page)
+}
+
+func (c *Client) loadPage(ctx context.Context, href string, page Page) error {
+	if href == "" {
+		return errors.New("Page has no link to follow")
+	}
+
+	// reset the page so no field of the current page survives
+	v := reflect.ValueOf(page).Elem()
+	v.Set(reflect.Zero(v.Type()))
+
+	return c.loadURL(ctx, href, page)
+}
+
+// PageIterator walks the pages of a collection by following the `next` link
+// of each page.  The page it was created with is updated in place:
+//
+//	page, err := client.LoadTransactions(address, horizon.PageRequest{})
+//	// check err
+//	it := client.Iterate(&page)
+//	for it.Next() {
+//		// use page.Embedded.Records
+//	}
+//	// check it.Err()
+type PageIterator struct {
+	ctx     context.Context
+	client  *Client
+	page    Page
+	started bool
+	err     error
+}
+
+// Iterate returns an iterator that walks the pages following (and including)
+// the provided page.
+func (c *Client) Iterate(page Page) *PageIterator {
+	return c.IterateContext(context.Background(), page)
+}
+
+// IterateContext is Iterate using the provided context to load pages.
+func (c *Client) IterateContext(ctx context.Context, page Page) *PageIterator {
+	return &PageIterator{ctx: ctx, client: c, page: page}
+}
+
+// Next advances the iterator to the next non-empty page, returning false
+// when there are no more records or an error occurred.
+func (it *PageIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if !it.started {
+		it.started = true
+		return it.page.Len() > 0
+	}
+
+	if it.page.Len() == 0 || it.page.Paging().Next.Href == "" {
+		return false
+	}
+
+	it.err = it.client.NextPageContext(it.ctx, it.page)
+	if it.err != nil {
+		return false
+	}
+
+	return it.page.Len() > 0
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PageIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,155 @@
+package horizon
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// Hooks are functions called around the requests sent by a Client, for
+// example to log them or to collect metrics.  Nil hooks are skipped.
+type Hooks struct {
+	// OnRequest is called before a request is sent
+	OnRequest func(req *http.Request)
+	// OnResponse is called once a request completed, successfully or not
+	OnResponse func(req *http.Request, resp *http.Response, err error, elapsed time.Duration)
+}
+
+// RetryPolicy decides whether a failed request is retried.  Every request
+// sent by the Client is safe to retry: loads are idempotent GETs and
+// submissions resend the same envelope.
+type RetryPolicy interface {
+	// Retry returns whether the request should be retried after the provided
+	// attempt (starting at 1) got resp or err, and the delay to wait before
+	// retrying.
+	Retry(req *http.Request, attempt int, resp *http.Response, err error) (time.Duration, bool)
+}
+
+// ExponentialBackoff is a RetryPolicy that retries transient failures up to
+// MaxAttempts attempts in total, doubling the delay after each attempt
+// starting from Initial, up to Max when not zero.
+type ExponentialBackoff struct {
+	MaxAttempts int
+	Initial     time.Duration
+	Max         time.Duration
+}
+
+// Retry implements RetryPolicy
+func (b ExponentialBackoff) Retry(
+	req *http.Request,
+	attempt int,
+	resp *http.Response,
+	err error,
+) (time.Duration, bool) {
+
+	if attempt >= b.MaxAttempts || !Retryable(resp, err) {
+		return 0, false
+	}
+
+	delay := b.Initial
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if b.Max != 0 && delay >= b.Max {
+			return b.Max, true
+		}
+	}
+
+	return delay, true
+}
+
+// Retryable returns true if a request that got resp or err failed in a way
+// that may succeed when retried: connection errors, timeouts, rate limiting
+// and server errors.
+func Retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return err != context.Canceled && err != context.DeadlineExceeded
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// contextHttpClient is implemented by http clients able to send requests
+// with headers and a context, such as *http.Client.
+type contextHttpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// do sends the request returned by newRequest, retrying it as allowed by the
+// client's retry policy.  newRequest is called for every attempt so that
+// request bodies can be resent.
+func (c *Client) do(
+	ctx context.Context,
+	newRequest func() (*http.Request, error),
+) (*http.Response, error) {
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.send(req.WithContext(ctx))
+		if c.Retry == nil || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay, retry := c.Retry.Retry(req, attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// send sends a single request, calling the client's hooks around it.
+func (c *Client) send(req *http.Request) (resp *http.Response, err error) {
+	c.initHttpClient()
+
+	if c.Hooks.OnRequest != nil {
+		c.Hooks.OnRequest(req)
+	}
+
+	start := time.Now()
+	if client, ok := c.Client.(contextHttpClient); ok {
+		resp, err = client.Do(req)
+	} else {
+		resp, err = c.sendWithoutContext(req)
+	}
+
+	if c.Hooks.OnResponse != nil {
+		c.Hooks.OnResponse(req, resp, err, time.Since(start))
+	}
+
+	return
+}
+
+// sendWithoutContext sends a request using a client that only implements
+// HorizonHttpClient.  The request's context is only checked before sending.
+func (c *Client) sendWithoutContext(req *http.Request) (*http.Response, error) {
+	err := req.Context().Err()
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Method == "POST" {
+		err = req.ParseForm()
+		if err != nil {
+			return nil, err
+		}
+		return c.Client.PostForm(req.URL.String(), req.PostForm)
+	}
+
+	return c.Client.Get(req.URL.String())
+}
@@ -0,0 +1,162 @@
+package horizon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Requests", func() {
+	var (
+		server   *httptest.Server
+		client   *Client
+		statuses []int
+		bodies   []string
+	)
+
+	// the server answers with the queued statuses, then with 200
+	BeforeEach(func() {
+		statuses, bodies = nil, nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.ParseForm()
+			bodies = append(bodies, r.PostForm.Get("tx"))
+
+			status := http.StatusOK
+			if len(statuses) > 0 {
+				status, statuses = statuses[0], statuses[1:]
+			}
+
+			w.WriteHeader(status)
+			if status == http.StatusOK {
+				fmt.Fprint(w, submitResponse)
+			} else {
+				fmt.Fprintf(w, `{"title": "Error", "status": %d}`, status)
+			}
+		}))
+		client = &Client{
+			URL:   server.URL,
+			Retry: ExponentialBackoff{MaxAttempts: 3, Initial: time.Millisecond},
+		}
+	})
+
+	AfterEach(func() { server.Close() })
+
+	Describe("retries", func() {
+		It("retries server errors", func() {
+			statuses = []int{http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+			_, err := client.LoadTransaction("abc")
+			Expect(err).To(BeNil())
+			Expect(bodies).To(HaveLen(3))
+		})
+
+		It("gives up after the maximum attempts", func() {
+			statuses = []int{500, 500, 500, 500}
+			_, err := client.LoadTransaction("abc")
+			horizonError, ok := err.(*Error)
+			Expect(ok).To(BeTrue())
+			Expect(horizonError.Problem.Status).To(Equal(500))
+			Expect(bodies).To(HaveLen(3))
+		})
+
+		It("does not retry client errors", func() {
+			statuses = []int{http.StatusNotFound}
+			_, err := client.LoadTransaction("abc")
+			Expect(err).NotTo(BeNil())
+			Expect(bodies).To(HaveLen(1))
+		})
+
+		It("resubmits the same envelope", func() {
+			statuses = []int{http.StatusGatewayTimeout}
+			response, err := client.SubmitTransaction("AAAA")
+			Expect(err).To(BeNil())
+			Expect(response.Ledger).To(Equal(int32(3128812)))
+			Expect(bodies).To(Equal([]string{"AAAA", "AAAA"}))
+		})
+
+		It("does not retry without a policy", func() {
+			client.Retry = nil
+			statuses = []int{http.StatusServiceUnavailable}
+			_, err := client.LoadTransaction("abc")
+			Expect(err).NotTo(BeNil())
+			Expect(bodies).To(HaveLen(1))
+		})
+	})
+
+	Describe("contexts", func() {
+		It("cancels in-flight requests", func() {
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+
+			_, err := client.LoadTransactionContext(ctx, "abc")
+			Expect(err).NotTo(BeNil())
+			Expect(ctx.Err()).To(Equal(context.DeadlineExceeded))
+		})
+
+		It("checks the context with clients that cannot send requests", func() {
+			client.Client = &TestHttpClient{Error: errors.New("should not be called")}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			_, err := client.LoadAccountContext(ctx, "abc")
+			Expect(err).To(Equal(context.Canceled))
+		})
+	})
+
+	Describe("hooks", func() {
+		It("are called for every attempt", func() {
+			var requests, responses []string
+			client.Hooks = Hooks{
+				OnRequest: func(req *http.Request) {
+					requests = append(requests, req.Method+" "+req.URL.Path)
+				},
+				OnResponse: func(req *http.Request, resp *http.Response, err error, elapsed time.Duration) {
+					responses = append(responses, fmt.Sprint(resp.StatusCode))
+				},
+			}
+
+			statuses = []int{http.StatusServiceUnavailable}
+			_, err := client.LoadTransaction("abc")
+			Expect(err).To(BeNil())
+			Expect(requests).To(Equal([]string{"GET /transactions/abc", "GET /transactions/abc"}))
+			Expect(responses).To(Equal([]string{"503", "200"}))
+		})
+	})
+})
+
+var _ = Describe("ExponentialBackoff", func() {
+	policy := ExponentialBackoff{MaxAttempts: 5, Initial: time.Second, Max: 3 * time.Second}
+	failed := &http.Response{StatusCode: http.StatusServiceUnavailable}
+
+	It("doubles the delay up to the maximum", func() {
+		var delays []time.Duration
+		for attempt := 1; attempt < 5; attempt++ {
+			delay, ok := policy.Retry(nil, attempt, failed, nil)
+			Expect(ok).To(BeTrue())
+			delays = append(delays, delay)
+		}
+		Expect(delays).To(Equal([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}))
+	})
+
+	It("stops after the maximum attempts", func() {
+		_, ok := policy.Retry(nil, 5, failed, nil)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("retries connection errors but not cancellations", func() {
+		_, ok := policy.Retry(nil, 1, nil, errors.New("connection reset"))
+		Expect(ok).To(BeTrue())
+		_, ok = policy.Retry(nil, 1, nil, context.Canceled)
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -1,6 +1,18 @@
 // This file contains response structs from horizon
 package horizon
 
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/hash"
+	"bitbucket.org/atticlab/go-smart-base/meta"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
 type Problem struct {
 	Type     string                 `json:"type"`
 	Title    string                 `json:"title"`
@@ -58,23 +70,150 @@ type Asset struct {
 	Issuer string `json:"asset_issuer,omitempty"`
 }
 
+// BuildAsset converts the asset into a build.Asset
+func (a Asset) BuildAsset() build.Asset {
+	if a.Type == "native" {
+		return build.NativeAsset()
+	}
+	return build.CreditAsset(a.Code, a.Issuer)
+}
+
 type Balance struct {
 	Balance string `json:"balance"`
 	Limit   string `json:"limit,omitempty"`
 	Asset
 }
 
+type Effect struct {
+	Links struct {
+		Operation Link `json:"operation"`
+		Succeeds  Link `json:"succeeds"`
+		Precedes  Link `json:"precedes"`
+	} `json:"_links"`
+
+	ID      string `json:"id"`
+	PT      string `json:"paging_token"`
+	Account string `json:"account"`
+	Type    string `json:"type"`
+	TypeI   int32  `json:"type_i"`
+
+	// Populated for account_credited and account_debited effects
+	Amount string `json:"amount,omitempty"`
+	Asset
+}
+
+type EffectsPage struct {
+	Links    PageLinks `json:"_links"`
+	Embedded struct {
+		Records []Effect `json:"records"`
+	} `json:"_embedded"`
+}
+
+func (p *EffectsPage) Paging() PageLinks { return p.Links }
+func (p *EffectsPage) Len() int          { return len(p.Embedded.Records) }
+
 type HistoryAccount struct {
 	ID        string `json:"id"`
 	PT        string `json:"paging_token"`
 	AccountID string `json:"account_id"`
 }
 
+type Ledger struct {
+	ID               string `json:"id"`
+	PT               string `json:"paging_token"`
+	Hash             string `json:"hash"`
+	PrevHash         string `json:"prev_hash,omitempty"`
+	Sequence         int32  `json:"sequence"`
+	TransactionCount int32  `json:"transaction_count"`
+	OperationCount   int32  `json:"operation_count"`
+	ClosedAt         string `json:"closed_at"`
+	TotalCoins       string `json:"total_coins"`
+	FeePool          string `json:"fee_pool"`
+	BaseFee          int32  `json:"base_fee"`
+	BaseReserve      string `json:"base_reserve"`
+	MaxTxSetSize     int32  `json:"max_tx_set_size"`
+	HeaderXDR        string `json:"header_xdr"`
+}
+
+// Header decodes the XDR header of the ledger, after verifying that its hash
+// matches the hash reported by horizon.
+func (l Ledger) Header() (header xdr.LedgerHeader, err error) {
+	if l.HeaderXDR == "" {
+		err = errors.New("Ledger has no header XDR")
+		return
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(l.HeaderXDR)
+	if err != nil {
+		return
+	}
+
+	h := hash.Hash(raw)
+	if actual := hex.EncodeToString(h[:]); actual != l.Hash {
+		err = fmt.Errorf("Ledger header hash %s does not match ledger hash %s", actual, l.Hash)
+		return
+	}
+
+	err = xdr.SafeUnmarshal(raw, &header)
+	return
+}
+
+type LedgersPage struct {
+	Links    PageLinks `json:"_links"`
+	Embedded struct {
+		Records []Ledger `json:"records"`
+	} `json:"_embedded"`
+}
+
+func (p *LedgersPage) Paging() PageLinks { return p.Links }
+func (p *LedgersPage) Len() int          { return len(p.Embedded.Records) }
+
 type Link struct {
 	Href      string `json:"href"`
 	Templated bool   `json:"templated,omitempty"`
 }
 
+type Transaction struct {
+	Links struct {
+		Self       Link `json:"self"`
+		Account    Link `json:"account"`
+		Ledger     Link `json:"ledger"`
+		Operations Link `json:"operations"`
+		Effects    Link `json:"effects"`
+		Succeeds   Link `json:"succeeds"`
+		Precedes   Link `json:"precedes"`
+	} `json:"_links"`
+
+	ID                    string   `json:"id"`
+	PT                    string   `json:"paging_token"`
+	Hash                  string   `json:"hash"`
+	Ledger                int32    `json:"ledger"`
+	CreatedAt             string   `json:"created_at"`
+	SourceAccount         string   `json:"source_account"`
+	SourceAccountSequence string   `json:"source_account_sequence"`
+	FeePaid               int32    `json:"fee_paid"`
+	OperationCount        int32    `json:"operation_count"`
+	Env                   string   `json:"envelope_xdr"`
+	Result                string   `json:"result_xdr"`
+	Meta                  string   `json:"result_meta_xdr"`
+	FeeMeta               string   `json:"fee_meta_xdr"`
+	MemoType              string   `json:"memo_type"`
+	Memo                  string   `json:"memo,omitempty"`
+	Signatures            []string `json:"signatures"`
+	ValidAfter            string   `json:"valid_after,omitempty"`
+	ValidBefore           string   `json:"valid_before,omitempty"`
+}
+
+type TransactionsPage struct {
+	Links    PageLinks `json:"_links"`
+	Embedded struct {
+		Records []Transaction `json:"records"`
+	} `json:"_embedded"`
+}
+
+func (p *TransactionsPage) Paging() PageLinks { return p.Links }
+func (p *TransactionsPage) Len() int          { return len(p.Embedded.Records) }
+
 type TransactionSuccess struct {
 	Links struct {
 		Transaction Link `json:"transaction"`
@@ -86,7 +225,205 @@ type TransactionSuccess struct {
 	Meta   string `json:"result_meta_xdr"`
 }
 
+// TransactionEnvelope decodes the envelope of the submitted transaction.
+func (t TransactionSuccess) TransactionEnvelope() (env xdr.TransactionEnvelope, err error) {
+	err = xdr.SafeUnmarshalBase64(t.Env, &env)
+	return
+}
+
+// TransactionResult decodes the result of the submitted transaction.
+func (t TransactionSuccess) TransactionResult() (result xdr.TransactionResult, err error) {
+	err = xdr.SafeUnmarshalBase64(t.Result, &result)
+	return
+}
+
+// TransactionMeta decodes the meta produced by the application of the
+// submitted transaction.
+func (t TransactionSuccess) TransactionMeta() (txMeta xdr.TransactionMeta, err error) {
+	err = xdr.SafeUnmarshalBase64(t.Meta, &txMeta)
+	return
+}
+
+// Bundle returns the meta of the submitted transaction as a meta.Bundle,
+// to query the state of ledger entries after its application.  Horizon does
+// not return the fee meta on submission, so the bundle only holds the
+// transaction meta.
+func (t TransactionSuccess) Bundle() (bundle meta.Bundle, err error) {
+	bundle.TransactionMeta, err = t.TransactionMeta()
+	return
+}
+
+type Operation struct {
+	Links struct {
+		Self        Link `json:"self"`
+		Transaction Link `json:"transaction"`
+		Effects     Link `json:"effects"`
+		Succeeds    Link `json:"succeeds"`
+		Precedes    Link `json:"precedes"`
+	} `json:"_links"`
+
+	ID            string `json:"id"`
+	PT            string `json:"paging_token"`
+	SourceAccount string `json:"source_account"`
+	Type          string `json:"type"`
+	TypeI         int32  `json:"type_i"`
+}
+
+type OperationsPage struct {
+	Links    PageLinks `json:"_links"`
+	Embedded struct {
+		Records []Operation `json:"records"`
+	} `json:"_embedded"`
+}
+
+func (p *OperationsPage) Paging() PageLinks { return p.Links }
+func (p *OperationsPage) Len() int          { return len(p.Embedded.Records) }
+
+// Offer is an open offer of an account, as returned by the offers endpoint
+type Offer struct {
+	Links struct {
+		Self       Link `json:"self"`
+		OfferMaker Link `json:"offer_maker"`
+	} `json:"_links"`
+
+	ID      uint64 `json:"id"`
+	PT      string `json:"paging_token"`
+	Seller  string `json:"seller"`
+	Selling Asset  `json:"selling"`
+	Buying  Asset  `json:"buying"`
+	Amount  string `json:"amount"`
+	PriceR  Price  `json:"price_r"`
+	Price   string `json:"price"`
+}
+
+// Rate returns the assets and price of the offer, as used to update or
+// delete it
+func (o Offer) Rate() build.Rate {
+	return build.Rate{
+		Selling: o.Selling.BuildAsset(),
+		Buying:  o.Buying.BuildAsset(),
+		Price:   build.Price(o.Price),
+	}
+}
+
+// OfferID returns the ID of the offer, as used to update or delete it
+func (o Offer) OfferID() build.OfferID {
+	return build.OfferID(o.ID)
+}
+
+type OffersPage struct {
+	Links    PageLinks `json:"_links"`
+	Embedded struct {
+		Records []Offer `json:"records"`
+	} `json:"_embedded"`
+}
+
+func (p *OffersPage) Paging() PageLinks { return p.Links }
+func (p *OffersPage) Len() int          { return len(p.Embedded.Records) }
+
+type OrderBookSummary struct {
+	Bids    []PriceLevel `json:"bids"`
+	Asks    []PriceLevel `json:"asks"`
+	Selling Asset        `json:"base"`
+	Buying  Asset        `json:"counter"`
+}
+
+// Rate returns the assets of the order book and the provided price, as used
+// to create an offer selling the selling asset
+func (o OrderBookSummary) Rate(price string) build.Rate {
+	return build.Rate{
+		Selling: o.Selling.BuildAsset(),
+		Buying:  o.Buying.BuildAsset(),
+		Price:   build.Price(price),
+	}
+}
+
+// Path is a payment path found by FindPaths
+type Path struct {
+	SourceAssetType        string  `json:"source_asset_type"`
+	SourceAssetCode        string  `json:"source_asset_code,omitempty"`
+	SourceAssetIssuer      string  `json:"source_asset_issuer,omitempty"`
+	SourceAmount           string  `json:"source_amount"`
+	DestinationAssetType   string  `json:"destination_asset_type"`
+	DestinationAssetCode   string  `json:"destination_asset_code,omitempty"`
+	DestinationAssetIssuer string  `json:"destination_asset_issuer,omitempty"`
+	DestinationAmount      string  `json:"destination_amount"`
+	Path                   []Asset `json:"path"`
+}
+
+// SourceAsset returns the asset sent through the path
+func (p Path) SourceAsset() Asset {
+	return Asset{Type: p.SourceAssetType, Code: p.SourceAssetCode, Issuer: p.SourceAssetIssuer}
+}
+
+// DestinationAsset returns the asset received through the path
+func (p Path) DestinationAsset() Asset {
+	return Asset{Type: p.DestinationAssetType, Code: p.DestinationAssetCode, Issuer: p.DestinationAssetIssuer}
+}
+
+// PayWith returns the build.PayWithPath mutator sending at most maxAmount of
+// the source asset through the intermediate assets of the path
+func (p Path) PayWith(maxAmount string) build.PayWithPath {
+	result := build.PayWith(p.SourceAsset().BuildAsset(), maxAmount)
+	for _, asset := range p.Path {
+		result = result.Through(asset.BuildAsset())
+	}
+	return result
+}
+
+type PathsPage struct {
+	Embedded struct {
+		Records []Path `json:"records"`
+	} `json:"_embedded"`
+}
+
+// Payment is an operation that moves funds, as returned by the payments
+// endpoints.  Only the fields matching its Type are populated.
+type Payment struct {
+	Operation
+
+	// Populated for payment and path_payment operations
+	From        string `json:"from,omitempty"`
+	To          string `json:"to,omitempty"`
+	Amount      string `json:"amount,omitempty"`
+	AssetType   string `json:"asset_type,omitempty"`
+	AssetCode   string `json:"asset_code,omitempty"`
+	AssetIssuer string `json:"asset_issuer,omitempty"`
+
+	// Populated for create_account operations
+	Funder          string `json:"funder,omitempty"`
+	Account         string `json:"account,omitempty"`
+	StartingBalance string `json:"starting_balance,omitempty"`
+}
+
+// Asset returns the asset of payment and path_payment operations
+func (p Payment) Asset() Asset {
+	return Asset{Type: p.AssetType, Code: p.AssetCode, Issuer: p.AssetIssuer}
+}
+
+type PaymentsPage struct {
+	Links    PageLinks `json:"_links"`
+	Embedded struct {
+		Records []Payment `json:"records"`
+	} `json:"_embedded"`
+}
+
+func (p *PaymentsPage) Paging() PageLinks { return p.Links }
+func (p *PaymentsPage) Len() int          { return len(p.Embedded.Records) }
+
+type Price struct {
+	N int32 `json:"n"`
+	D int32 `json:"d"`
+}
+
+type PriceLevel struct {
+	PriceR Price  `json:"price_r"`
+	Price  string `json:"price"`
+	Amount string `json:"amount"`
+}
+
 type Signer struct {
-	PublicKey string `json:"public_key"`
-	Weight    int32  `json:"weight"`
+	PublicKey  string `json:"public_key"`
+	Weight     int32  `json:"weight"`
+	SignerType int32  `json:"signer_type"`
 }
@@ -0,0 +1,223 @@
+package horizon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultStreamRetry is the time waited before reconnecting a stream, unless
+// horizon requests another delay.
+var DefaultStreamRetry = time.Second
+
+// LedgerHandler is called for each ledger received by StreamLedgers
+type LedgerHandler func(Ledger)
+
+// PaymentHandler is called for each payment received by StreamPayments
+type PaymentHandler func(Payment)
+
+// TransactionHandler is called for each transaction received by
+// StreamTransactions
+type TransactionHandler func(Transaction)
+
+// StreamLedgers streams the ledgers closed after cursor, calling handler for
+// each of them.  Use "now" as cursor to only receive new ledgers.  It blocks
+// until ctx is done or an error that reconnecting cannot fix occurs.
+func (c *Client) StreamLedgers(ctx context.Context, cursor string, handler LedgerHandler) error {
+	return c.stream(ctx, "/ledgers", cursor, func(data []byte) (string, error) {
+		var ledger Ledger
+		err := json.Unmarshal(data, &ledger)
+		if err != nil {
+			return "", err
+		}
+
+		handler(ledger)
+		return ledger.PT, nil
+	})
+}
+
+// StreamPayments streams the payments made after cursor, calling handler for
+// each of them.  When accountID is not empty only the payments of that
+// account are streamed.  It blocks until ctx is done or an error that
+// reconnecting cannot fix occurs.
+func (c *Client) StreamPayments(ctx context.Context, accountID string, cursor string, handler PaymentHandler) error {
+	return c.stream(ctx, accountPath(accountID, "/payments"), cursor, func(data []byte) (string, error) {
+		var payment Payment
+		err := json.Unmarshal(data, &payment)
+		if err != nil {
+			return "", err
+		}
+
+		handler(payment)
+		return payment.PT, nil
+	})
+}
+
+// StreamTransactions streams the transactions applied after cursor, calling
+// handler for each of them.  When accountID is not empty only the
+// transactions of that account are streamed.  It blocks until ctx is done or
+// an error that reconnecting cannot fix occurs.
+func (c *Client) StreamTransactions(ctx context.Context, accountID string, cursor string, handler TransactionHandler) error {
+	return c.stream(ctx, accountPath(accountID, "/transactions"), cursor, func(data []byte) (string, error) {
+		var transaction Transaction
+		err := json.Unmarshal(data, &transaction)
+		if err != nil {
+			return "", err
+		}
+
+		handler(transaction)
+		return transaction.PT, nil
+	})
+}
+
+// stream connects to the event stream at path, calling onRecord with the data
+// of every record event.  onRecord returns the paging token of the record,
+// used to resume the stream after a reconnection.
+func (c *Client) stream(
+	ctx context.Context,
+	path string,
+	cursor string,
+	onRecord func(data []byte) (string, error),
+) error {
+
+	c.initHttpClient()
+	if _, ok := c.Client.(contextHttpClient); !ok {
+		return errors.New("HTTP client does not support streaming")
+	}
+
+	retry := DefaultStreamRetry
+
+	for {
+		var recordErr error
+
+		resp, err := c.connect(ctx, path, cursor)
+		if err == nil {
+			if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+				return decodeResponse(resp, nil)
+			}
+
+			// read errors are handled by reconnecting
+			readEvents(resp.Body, func(ev event) bool {
+				// stop delivering buffered events once cancelled
+				if ctx.Err() != nil {
+					return false
+				}
+
+				if ev.retry > 0 {
+					retry = ev.retry
+				}
+
+				// skip the greeting and other non record messages
+				if !bytes.HasPrefix(ev.data, []byte("{")) {
+					return true
+				}
+
+				pt, err := onRecord(ev.data)
+				if err != nil {
+					recordErr = err
+					return false
+				}
+
+				if pt != "" {
+					cursor = pt
+				}
+				return true
+			})
+			resp.Body.Close()
+		}
+
+		if recordErr != nil {
+			return recordErr
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retry):
+		}
+	}
+}
+
+func (c *Client) connect(
+	ctx context.Context,
+	path string,
+	cursor string,
+) (*http.Response, error) {
+
+	u := c.URL + path
+	if cursor != "" {
+		u += "?" + url.Values{"cursor": []string{cursor}}.Encode()
+	}
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	return c.send(req.WithContext(ctx))
+}
+
+// event is a single server-sent event
+type event struct {
+	data  []byte
+	retry time.Duration
+}
+
+// readEvents reads server-sent events from r until it is exhausted or fn
+// returns false.
+func readEvents(r io.Reader, fn func(event) bool) error {
+	var (
+		ev      event
+		hasData bool
+	)
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if (hasData || ev.retry > 0) && !fn(ev) {
+				return nil
+			}
+			ev, hasData = event{}, false
+			continue
+		}
+
+		// comments
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		switch field {
+		case "data":
+			if hasData {
+				ev.data = append(ev.data, '\n')
+			}
+			ev.data = append(ev.data, value...)
+			hasData = true
+		case "retry":
+			ms, err := strconv.Atoi(value)
+			if err == nil {
+				ev.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package horizon
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Streaming", func() {
+	var (
+		server  *httptest.Server
+		client  *Client
+		mu      sync.Mutex
+		cursors []string
+	)
+
+	// each connection sends the greeting and the next two records, then
+	// closes the stream so the client has to reconnect
+	BeforeEach(func() {
+		cursors = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			cursors = append(cursors, r.URL.Query().Get("cursor"))
+			mu.Unlock()
+
+			if r.Header.Get("Accept") != "text/event-stream" {
+				w.WriteHeader(http.StatusNotAcceptable)
+				fmt.Fprint(w, `{"title": "Not Acceptable", "status": 406}`)
+				return
+			}
+
+			var start int
+			fmt.Sscan(r.URL.Query().Get("cursor"), &start)
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "retry: 10\nevent: open\ndata: \"hello\"\n\n")
+			for pt := start + 1; pt <= start+2; pt++ {
+				fmt.Fprintf(w, "id: %d\ndata: {\"id\": \"%d\", \"paging_token\": \"%d\", \"sequence\": %d}\n\n", pt, pt, pt, pt)
+			}
+		}))
+		client = &Client{URL: server.URL}
+	})
+
+	AfterEach(func() { server.Close() })
+
+	Describe("StreamLedgers", func() {
+		It("reconnects from the last paging token", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			var sequences []int32
+			err := client.StreamLedgers(ctx, "", func(l Ledger) {
+				sequences = append(sequences, l.Sequence)
+				if len(sequences) == 5 {
+					cancel()
+				}
+			})
+
+			Expect(err).To(Equal(context.Canceled))
+			Expect(sequences).To(Equal([]int32{1, 2, 3, 4, 5}))
+
+			mu.Lock()
+			defer mu.Unlock()
+			Expect(cursors[:3]).To(Equal([]string{"", "2", "4"}))
+		})
+	})
+
+	Describe("StreamPayments", func() {
+		It("requests the account's payments", func() {
+			var path string
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				fmt.Fprint(w, "data: {\"paging_token\": \"7\", \"type\": \"payment\", \"amount\": \"10.0000000\"}\n\n")
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var payments []Payment
+			err := client.StreamPayments(ctx, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", "now", func(p Payment) {
+				payments = append(payments, p)
+				cancel()
+			})
+
+			Expect(err).To(Equal(context.Canceled))
+			Expect(path).To(Equal("/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/payments"))
+			Expect(payments).To(HaveLen(1))
+			Expect(payments[0].Amount).To(Equal("10.0000000"))
+		})
+	})
+
+	Describe("StreamTransactions", func() {
+		It("returns horizon errors", func() {
+			server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, notFoundResponse)
+			})
+
+			err := client.StreamTransactions(context.Background(), "", "", func(Transaction) {})
+			horizonError, ok := err.(*Error)
+			Expect(ok).To(BeTrue())
+			Expect(horizonError.Problem.Status).To(Equal(404))
+		})
+
+		It("fails with a client that cannot stream", func() {
+			client.Client = &TestHttpClient{}
+			err := client.StreamTransactions(context.Background(), "", "", func(Transaction) {})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,477 @@
+package horizontest
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+
+	"bitbucket.org/atticlab/go-smart-base/keypair"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// apply applies the transaction, returning its result and, when successful,
+// its meta.  Transactions that fail consume their sequence without changing
+// any balance.  err is only returned for transactions the server does not
+// support.
+func (s *Server) apply(
+	e *xdr.TransactionEnvelope,
+	hash [32]byte,
+) (result xdr.TransactionResult, meta xdr.TransactionMeta, err error) {
+
+	tx := &e.Tx
+	for _, op := range tx.Operations {
+		switch op.Body.Type {
+		case xdr.OperationTypeCreateAccount, xdr.OperationTypePayment, xdr.OperationTypeChangeTrust:
+		default:
+			err = fmt.Errorf("Operation %s is not supported", op.Body.Type)
+			return
+		}
+	}
+
+	if len(tx.Operations) == 0 {
+		result.Result.Code = xdr.TransactionResultCodeTxMissingOperation
+		return
+	}
+
+	sourceAddress := tx.SourceAccount.Address()
+	source, ok := s.accounts[sourceAddress]
+	if !ok {
+		result.Result.Code = xdr.TransactionResultCodeTxNoAccount
+		return
+	}
+
+	if tx.SeqNum != source.sequence+1 {
+		result.Result.Code = xdr.TransactionResultCodeTxBadSeq
+		return
+	}
+
+	signed := signers(e, hash)
+	if !signed[sourceAddress] {
+		result.Result.Code = xdr.TransactionResultCodeTxBadAuth
+		return
+	}
+
+	// operations are applied to a copy of the accounts, kept only if all of
+	// them succeed
+	state := cloneAccounts(s.accounts)
+	state[sourceAddress].sequence = tx.SeqNum
+
+	failed := false
+	results := make([]xdr.OperationResult, len(tx.Operations))
+	operations := make([]xdr.OperationMeta, len(tx.Operations))
+	for i, op := range tx.Operations {
+		before := cloneAccounts(state)
+
+		opSource := sourceAddress
+		if op.SourceAccount != nil {
+			opSource = op.SourceAccount.Address()
+		}
+
+		switch {
+		case state[opSource] == nil:
+			results[i].Code = xdr.OperationResultCodeOpNoAccount
+			failed = true
+		case !signed[opSource]:
+			results[i].Code = xdr.OperationResultCodeOpBadAuth
+			failed = true
+		default:
+			var succeeded bool
+			results[i], succeeded = s.applyOperation(state, opSource, op)
+			failed = failed || !succeeded
+		}
+
+		operations[i].Changes, err = s.changes(before, state)
+		if err != nil {
+			return
+		}
+	}
+
+	result.Result.Results = &results
+	if failed {
+		result.Result.Code = xdr.TransactionResultCodeTxFailed
+		source.sequence = tx.SeqNum
+		return
+	}
+
+	result.Result.Code = xdr.TransactionResultCodeTxSuccess
+	meta.Operations = &operations
+	s.accounts = state
+	return
+}
+
+// applyOperation applies a supported operation made by source to state
+func (s *Server) applyOperation(
+	state map[string]*account,
+	source string,
+	op xdr.Operation,
+) (result xdr.OperationResult, succeeded bool) {
+
+	result.Tr = &xdr.OperationResultTr{Type: op.Body.Type}
+
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		code := s.createAccount(state, source, op.Body.MustCreateAccountOp())
+		result.Tr.CreateAccountResult = &xdr.CreateAccountResult{Code: code}
+		succeeded = code == xdr.CreateAccountResultCodeCreateAccountSuccess
+	case xdr.OperationTypePayment:
+		p := op.Body.MustPaymentOp()
+		code := transfer(state, source, p.Destination.Address(), p.Asset, p.Amount)
+		result.Tr.PaymentResult = &xdr.PaymentResult{Code: code}
+		succeeded = code == xdr.PaymentResultCodePaymentSuccess
+	case xdr.OperationTypeChangeTrust:
+		code := changeTrust(state, source, op.Body.MustChangeTrustOp())
+		result.Tr.ChangeTrustResult = &xdr.ChangeTrustResult{Code: code}
+		succeeded = code == xdr.ChangeTrustResultCodeChangeTrustSuccess
+	}
+
+	return
+}
+
+// createAccount creates the destination account, funding scratch cards from
+// the source account
+func (s *Server) createAccount(
+	state map[string]*account,
+	source string,
+	op xdr.CreateAccountOp,
+) xdr.CreateAccountResultCode {
+
+	destination := op.Destination.Address()
+	if destination == source {
+		return xdr.CreateAccountResultCodeCreateAccountMalformed
+	}
+
+	if _, ok := state[destination]; ok {
+		return xdr.CreateAccountResultCodeCreateAccountAlreadyExist
+	}
+
+	state[destination] = &account{
+		sequence: xdr.SequenceNumber(int64(s.ledger) << 32),
+		balances: map[string]*balance{},
+	}
+
+	card := op.Body.ScratchCard
+	if card == nil {
+		return xdr.CreateAccountResultCodeCreateAccountSuccess
+	}
+
+	if card.Asset.Type != xdr.AssetTypeAssetTypeNative {
+		state[destination].trust(card.Asset, math.MaxInt64)
+	}
+
+	switch transfer(state, source, destination, card.Asset, card.Amount) {
+	case xdr.PaymentResultCodePaymentSuccess:
+		return xdr.CreateAccountResultCodeCreateAccountSuccess
+	case xdr.PaymentResultCodePaymentMalformed:
+		return xdr.CreateAccountResultCodeCreateAccountMalformed
+	case xdr.PaymentResultCodePaymentNoIssuer:
+		return xdr.CreateAccountResultCodeCreateAccountNoIssuer
+	case xdr.PaymentResultCodePaymentLineFull:
+		return xdr.CreateAccountResultCodeCreateAccountLineFull
+	default:
+		return xdr.CreateAccountResultCodeCreateAccountUnderfunded
+	}
+}
+
+// transfer moves value of asset from source to destination.  Credit assets
+// are issued when sent by their issuer and burned when sent to it.
+func transfer(
+	state map[string]*account,
+	source string,
+	destination string,
+	asset xdr.Asset,
+	value xdr.Int64,
+) xdr.PaymentResultCode {
+
+	if value <= 0 {
+		return xdr.PaymentResultCodePaymentMalformed
+	}
+
+	to, ok := state[destination]
+	if !ok {
+		return xdr.PaymentResultCodePaymentNoDestination
+	}
+
+	var issuer string
+	if asset.Type != xdr.AssetTypeAssetTypeNative {
+		var typ, code string
+		asset.MustExtract(&typ, &code, &issuer)
+		if _, ok := state[issuer]; !ok {
+			return xdr.PaymentResultCodePaymentNoIssuer
+		}
+	}
+
+	key := asset.String()
+
+	var from *balance
+	if source != issuer {
+		from, ok = state[source].balances[key]
+		if !ok {
+			return xdr.PaymentResultCodePaymentSrcNoTrust
+		}
+		if from.amount < value {
+			return xdr.PaymentResultCodePaymentUnderfunded
+		}
+	}
+
+	var into *balance
+	if destination != issuer {
+		into, ok = to.balances[key]
+		if !ok {
+			if asset.Type != xdr.AssetTypeAssetTypeNative {
+				return xdr.PaymentResultCodePaymentNoTrust
+			}
+			into = to.trust(asset, 0)
+		}
+		if into.available() < value {
+			return xdr.PaymentResultCodePaymentLineFull
+		}
+	}
+
+	if from != nil {
+		from.amount -= value
+	}
+	if into != nil {
+		into.amount += value
+	}
+	return xdr.PaymentResultCodePaymentSuccess
+}
+
+// changeTrust adds, updates or removes (when the limit is zero) a trust line
+// of the source account
+func changeTrust(
+	state map[string]*account,
+	source string,
+	op xdr.ChangeTrustOp,
+) xdr.ChangeTrustResultCode {
+
+	if op.Line.Type == xdr.AssetTypeAssetTypeNative || op.Limit < 0 {
+		return xdr.ChangeTrustResultCodeChangeTrustMalformed
+	}
+
+	var typ, code, issuer string
+	op.Line.MustExtract(&typ, &code, &issuer)
+	if issuer == source {
+		return xdr.ChangeTrustResultCodeChangeTrustMalformed
+	}
+	if _, ok := state[issuer]; !ok {
+		return xdr.ChangeTrustResultCodeChangeTrustNoIssuer
+	}
+
+	a := state[source]
+	key := op.Line.String()
+	if b, ok := a.balances[key]; ok && b.amount > op.Limit {
+		return xdr.ChangeTrustResultCodeChangeTrustInvalidLimit
+	}
+
+	if op.Limit == 0 {
+		delete(a.balances, key)
+	} else {
+		a.trust(op.Line, op.Limit).limit = op.Limit
+	}
+
+	return xdr.ChangeTrustResultCodeChangeTrustSuccess
+}
+
+// changes returns the changes of the ledger entries of the accounts and trust
+// lines from before to after, ordered by account, as stellar-core reports them:
+// updated and removed entries are preceded by their prior state.  Changed
+// entries are last modified in the ledger being closed, and prior states in
+// the ledger before it.
+func (s *Server) changes(before, after map[string]*account) (ret xdr.LedgerEntryChanges, err error) {
+	addresses := make([]string, 0, len(after))
+	for address := range after {
+		addresses = append(addresses, address)
+	}
+	for address := range before {
+		if _, ok := after[address]; !ok {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		var prior, current map[string]xdr.LedgerEntry
+		prior, err = entries(address, before[address])
+		if err != nil {
+			return
+		}
+		current, err = entries(address, after[address])
+		if err != nil {
+			return
+		}
+
+		// the account entry is keyed by the empty string, so that it sorts
+		// before the trust lines of the account
+		keys := make([]string, 0, len(prior)+len(current))
+		for key := range prior {
+			keys = append(keys, key)
+		}
+		for key := range current {
+			if _, ok := prior[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			was, existed := prior[key]
+			now, exists := current[key]
+			if existed && exists && reflect.DeepEqual(was, now) {
+				continue
+			}
+
+			was.LastModifiedLedgerSeq = xdr.Uint32(s.ledger)
+			now.LastModifiedLedgerSeq = xdr.Uint32(s.ledger + 1)
+			if existed {
+				ret = append(ret, xdr.LedgerEntryChange{
+					Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+					State: &was,
+				})
+			}
+
+			switch {
+			case !existed:
+				ret = append(ret, xdr.LedgerEntryChange{
+					Type:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+					Created: &now,
+				})
+			case !exists:
+				removed := was.LedgerKey()
+				ret = append(ret, xdr.LedgerEntryChange{
+					Type:    xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+					Removed: &removed,
+				})
+			default:
+				ret = append(ret, xdr.LedgerEntryChange{
+					Type:    xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+					Updated: &now,
+				})
+			}
+		}
+	}
+
+	return
+}
+
+// entries returns the ledger entries of an account, if any: the account
+// entry, keyed by the empty string, and its trust lines, keyed by asset
+func entries(address string, a *account) (map[string]xdr.LedgerEntry, error) {
+	ret := map[string]xdr.LedgerEntry{}
+	if a == nil {
+		return ret, nil
+	}
+
+	var id xdr.AccountId
+	err := id.SetAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := xdr.AccountEntry{
+		AccountId:  id,
+		SeqNum:     a.sequence,
+		Thresholds: xdr.Thresholds{1, 0, 0, 0},
+	}
+
+	for key, b := range a.balances {
+		if b.asset.Type == xdr.AssetTypeAssetTypeNative {
+			entry.Balance = b.amount
+			continue
+		}
+
+		entry.NumSubEntries++
+		ret[key] = xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeTrustline,
+				TrustLine: &xdr.TrustLineEntry{
+					AccountId: id,
+					Asset:     b.asset,
+					Balance:   b.amount,
+					Limit:     b.limit,
+					Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+				},
+			},
+		}
+	}
+
+	ret[""] = xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &entry,
+		},
+	}
+	return ret, nil
+}
+
+// signers returns the addresses whose signatures of the transaction hash are
+// included in the envelope
+func signers(e *xdr.TransactionEnvelope, hash [32]byte) map[string]bool {
+	addresses := []string{e.Tx.SourceAccount.Address()}
+	for _, op := range e.Tx.Operations {
+		if op.SourceAccount != nil {
+			addresses = append(addresses, op.SourceAccount.Address())
+		}
+	}
+
+	signed := map[string]bool{}
+	for _, address := range addresses {
+		kp, err := keypair.Parse(address)
+		if err != nil {
+			continue
+		}
+
+		for _, sig := range e.Signatures {
+			if xdr.SignatureHint(kp.Hint()) == sig.Hint && kp.Verify(hash[:], sig.Signature) == nil {
+				signed[address] = true
+				break
+			}
+		}
+	}
+
+	return signed
+}
+
+// trust returns the balance of the account in asset, adding it with the
+// provided limit when missing
+func (a *account) trust(asset xdr.Asset, limit xdr.Int64) *balance {
+	key := asset.String()
+	b, ok := a.balances[key]
+	if !ok {
+		b = &balance{asset: asset}
+		if asset.Type != xdr.AssetTypeAssetTypeNative {
+			b.limit = limit
+		}
+		a.balances[key] = b
+	}
+	return b
+}
+
+// cloneAccounts returns a deep copy of accounts
+func cloneAccounts(accounts map[string]*account) map[string]*account {
+	ret := make(map[string]*account, len(accounts))
+	for address, a := range accounts {
+		ret[address] = a.clone()
+	}
+	return ret
+}
+
+func (a *account) clone() *account {
+	ret := &account{
+		sequence: a.sequence,
+		balances: make(map[string]*balance, len(a.balances)),
+	}
+	for key, b := range a.balances {
+		copied := *b
+		ret.balances[key] = &copied
+	}
+	return ret
+}
+
+// available returns the amount that can still be added to the balance
+func (b *balance) available() xdr.Int64 {
+	if b.asset.Type == xdr.AssetTypeAssetTypeNative {
+		return math.MaxInt64 - b.amount
+	}
+	return b.limit - b.amount
+}
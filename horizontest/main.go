@@ -0,0 +1,257 @@
+// Package horizontest provides an in-process fake horizon server, so that
+// code using horizon.Client can be tested without a network.
+//
+// The server keeps accounts, sequence numbers and balances in memory.  It
+// accepts submitted transactions, verifies their sequence and signatures and
+// applies their create account, payment and change trust operations,
+// answering with the same success and failure responses as horizon.  The meta
+// of successful transactions records the account and trust line entries
+// created, updated and removed by each operation.  Other operations are
+// rejected as malformed, and fees are not charged.
+package horizontest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/horizon"
+	"bitbucket.org/atticlab/go-smart-base/keypair"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// DefaultBaseFee is the base fee reported by new servers
+const DefaultBaseFee = 100
+
+// Server is a fake horizon server.  It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Network is the network transactions must be signed for
+	Network build.Network
+	// BaseFee is the base fee reported in the latest ledger
+	BaseFee int32
+
+	lock         sync.Mutex
+	ledger       int32
+	accounts     map[string]*account
+	transactions map[string]horizon.Transaction
+}
+
+// account is the state of an account known to the server
+type account struct {
+	sequence xdr.SequenceNumber
+	balances map[string]*balance
+}
+
+// balance is a balance of an account, keyed by the string form of its asset.
+// Native balances have no limit.
+type balance struct {
+	asset  xdr.Asset
+	amount xdr.Int64
+	limit  xdr.Int64
+}
+
+// NewServer starts a fake horizon server accepting transactions signed for
+// the provided network.  Close the server once done.
+func NewServer(network build.Network) *Server {
+	s := &Server{
+		Network:      network,
+		BaseFee:      DefaultBaseFee,
+		ledger:       1,
+		accounts:     map[string]*account{},
+		transactions: map[string]horizon.Transaction{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a horizon client connected to the server
+func (s *Server) Client() *horizon.Client {
+	return &horizon.Client{URL: s.URL, Client: s.Server.Client()}
+}
+
+// AddAccount adds an account with the provided sequence and no balances.
+// Existing accounts are reset.
+func (s *Server) AddAccount(address string, sequence xdr.SequenceNumber) error {
+	if _, err := keypair.Parse(address); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.accounts[address] = &account{
+		sequence: sequence,
+		balances: map[string]*balance{},
+	}
+	return nil
+}
+
+// SetBalance sets the balance of an existing account, adding a trust line
+// with the maximum limit for credit assets the account does not trust yet.
+func (s *Server) SetBalance(address string, asset build.Asset, value string) error {
+	xdrAsset, err := asset.ToXdrObject()
+	if err != nil {
+		return err
+	}
+
+	parsed, err := amount.Parse(value)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	a, ok := s.accounts[address]
+	if !ok {
+		return fmt.Errorf("Account %s not found", address)
+	}
+
+	b := a.trust(xdrAsset, math.MaxInt64)
+	b.amount = parsed
+	return nil
+}
+
+// Balance returns the balance of the account in the provided asset, or an
+// error if the account does not exist or hold the asset.
+func (s *Server) Balance(address string, asset build.Asset) (string, error) {
+	xdrAsset, err := asset.ToXdrObject()
+	if err != nil {
+		return "", err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	a, ok := s.accounts[address]
+	if !ok {
+		return "", fmt.Errorf("Account %s not found", address)
+	}
+
+	b, ok := a.balances[xdrAsset.String()]
+	if !ok {
+		return "", fmt.Errorf("Account %s does not hold %s", address, xdrAsset.String())
+	}
+
+	return amount.String(b.amount), nil
+}
+
+// Sequence returns the current sequence of the account
+func (s *Server) Sequence(address string) (xdr.SequenceNumber, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	a, ok := s.accounts[address]
+	if !ok {
+		return 0, fmt.Errorf("Account %s not found", address)
+	}
+
+	return a.sequence, nil
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case r.Method == "POST" && path == "/transactions":
+		s.submit(w, r)
+	case r.Method == "GET" && strings.HasPrefix(path, "/accounts/"):
+		s.loadAccount(w, strings.TrimPrefix(path, "/accounts/"))
+	case r.Method == "GET" && strings.HasPrefix(path, "/transactions/"):
+		s.loadTransaction(w, strings.TrimPrefix(path, "/transactions/"))
+	case r.Method == "GET" && path == "/ledgers":
+		s.loadLedgers(w)
+	default:
+		writeNotFound(w)
+	}
+}
+
+func (s *Server) loadAccount(w http.ResponseWriter, address string) {
+	a, ok := s.accounts[address]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	var ret horizon.Account
+	ret.Links.Self.Href = s.URL + "/accounts/" + address
+	ret.ID = address
+	ret.PT = address
+	ret.AccountID = address
+	ret.Sequence = fmt.Sprint(a.sequence)
+	ret.Signers = []horizon.Signer{{PublicKey: address, Weight: 1}}
+	ret.Balances = []horizon.Balance{}
+
+	for _, b := range a.balances {
+		var rb horizon.Balance
+		b.asset.MustExtract(&rb.Type, &rb.Code, &rb.Issuer)
+		rb.Balance = amount.String(b.amount)
+		if b.asset.Type != xdr.AssetTypeAssetTypeNative {
+			rb.Limit = amount.String(b.limit)
+		}
+		ret.Balances = append(ret.Balances, rb)
+	}
+
+	writeJSON(w, http.StatusOK, ret)
+}
+
+func (s *Server) loadTransaction(w http.ResponseWriter, hash string) {
+	tx, ok := s.transactions[hash]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tx)
+}
+
+// loadLedgers returns a page holding the latest ledger, whatever the paging
+// parameters are
+func (s *Server) loadLedgers(w http.ResponseWriter) {
+	ledger, err := s.latestLedger()
+	if err != nil {
+		writeProblem(w, horizon.Problem{
+			Type:   "server_error",
+			Title:  "Internal Server Error",
+			Status: http.StatusInternalServerError,
+			Detail: err.Error(),
+		})
+		return
+	}
+
+	var page horizon.LedgersPage
+	page.Links.Self.Href = s.URL + "/ledgers"
+	page.Embedded.Records = []horizon.Ledger{ledger}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func writeJSON(w http.ResponseWriter, status int, object interface{}) {
+	w.Header().Set("Content-Type", "application/hal+json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(object)
+}
+
+func writeProblem(w http.ResponseWriter, problem horizon.Problem) {
+	w.Header().Set("Content-Type", "application/problem+json; charset=utf-8")
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeProblem(w, horizon.Problem{
+		Type:   "not_found",
+		Title:  "Resource Missing",
+		Status: http.StatusNotFound,
+		Detail: "The resource at the url requested was not found.",
+	})
+}
@@ -0,0 +1,318 @@
+package horizontest
+
+import (
+	"context"
+	"testing"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/horizon"
+	"bitbucket.org/atticlab/go-smart-base/keypair"
+	"bitbucket.org/atticlab/go-smart-base/txsub"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHorizontest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Package: bitbucket.org/atticlab/go-smart-base/horizontest")
+}
+
+var _ = Describe("Server", func() {
+	var (
+		server           *Server
+		client           *horizon.Client
+		issuer, from, to *keypair.Full
+		usd              build.Asset
+	)
+
+	random := func() *keypair.Full {
+		kp, err := keypair.Random()
+		Expect(err).NotTo(HaveOccurred())
+		return kp
+	}
+
+	submit := func(source *keypair.Full, seq xdr.SequenceNumber, muts ...build.TransactionMutator) error {
+		all := append([]build.TransactionMutator{
+			build.SourceAccount{AddressOrSeed: source.Address()},
+			build.Sequence{Sequence: uint64(seq)},
+			build.TestNetwork,
+		}, muts...)
+		tx := build.Transaction(all...)
+		envelope := tx.Sign(source.Seed())
+
+		b64, err := envelope.Base64()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.SubmitTransaction(b64)
+		return err
+	}
+
+	payment := func(value string) build.PaymentBuilder {
+		return build.Payment(
+			build.Destination{AddressOrSeed: to.Address()},
+			build.CreditAmount{Code: "USD", Issuer: issuer.Address(), Amount: value},
+		)
+	}
+
+	BeforeEach(func() {
+		server = NewServer(build.TestNetwork)
+		client = server.Client()
+
+		issuer, from, to = random(), random(), random()
+		usd = build.CreditAsset("USD", issuer.Address())
+
+		Expect(server.AddAccount(issuer.Address(), 100)).To(Succeed())
+		Expect(server.AddAccount(from.Address(), 200)).To(Succeed())
+		Expect(server.AddAccount(to.Address(), 300)).To(Succeed())
+		Expect(server.SetBalance(from.Address(), usd, "50")).To(Succeed())
+	})
+
+	AfterEach(func() { server.Close() })
+
+	It("serves accounts", func() {
+		account, err := client.LoadAccount(from.Address())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(account.AccountID).To(Equal(from.Address()))
+		Expect(account.Sequence).To(Equal("200"))
+		Expect(account.Balances).To(HaveLen(1))
+		Expect(account.Balances[0].Balance).To(Equal("50.0000000"))
+		Expect(account.Balances[0].Asset.BuildAsset()).To(Equal(usd))
+
+		_, err = client.LoadAccount(random().Address())
+		herr, ok := err.(*horizon.Error)
+		Expect(ok).To(BeTrue())
+		Expect(herr.Problem.Status).To(Equal(404))
+	})
+
+	It("serves a verifiable latest ledger", func() {
+		fee, err := client.BaseFee()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fee).To(BeEquivalentTo(DefaultBaseFee))
+
+		ledger, err := client.LoadLatestLedger()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ledger.Header()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("applies payments, creating and trusting accounts", func() {
+		newAccount := random()
+		err := submit(from, 201,
+			build.CreateAccount(build.Destination{AddressOrSeed: newAccount.Address()}),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		seq, err := client.SequenceForAccount(newAccount.Address())
+		Expect(err).NotTo(HaveOccurred())
+
+		err = submit(newAccount, seq+1, build.Trust("USD", issuer.Address()))
+		Expect(err).NotTo(HaveOccurred())
+
+		err = submit(from, 202, build.Payment(
+			build.Destination{AddressOrSeed: newAccount.Address()},
+			build.CreditAmount{Code: "USD", Issuer: issuer.Address(), Amount: "20"},
+		))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(server.Balance(from.Address(), usd)).To(Equal("30.0000000"))
+		Expect(server.Balance(newAccount.Address(), usd)).To(Equal("20.0000000"))
+		Expect(server.Sequence(from.Address())).To(BeEquivalentTo(202))
+	})
+
+	It("returns successful transactions", func() {
+		Expect(server.SetBalance(to.Address(), usd, "0")).To(Succeed())
+
+		tx := build.Transaction(
+			build.SourceAccount{AddressOrSeed: from.Address()},
+			build.Sequence{Sequence: 201},
+			build.TestNetwork,
+			payment("5"),
+		)
+		envelope := tx.Sign(from.Seed())
+		b64, err := envelope.Base64()
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err := client.SubmitTransaction(b64)
+		Expect(err).NotTo(HaveOccurred())
+		hash, err := tx.HashHex()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Hash).To(Equal(hash))
+
+		result, err := response.TransactionResult()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Result.Code).To(Equal(xdr.TransactionResultCodeTxSuccess))
+
+		loaded, err := client.LoadTransaction(hash)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.SourceAccount).To(Equal(from.Address()))
+		Expect(loaded.Ledger).To(Equal(response.Ledger))
+	})
+
+	It("records ledger entry changes in the meta", func() {
+		newAccount := random()
+		tx := build.Transaction(
+			build.SourceAccount{AddressOrSeed: from.Address()},
+			build.Sequence{Sequence: 201},
+			build.TestNetwork,
+			build.CreateAccount(build.Destination{AddressOrSeed: newAccount.Address()}),
+			payment("5"),
+		)
+		Expect(server.SetBalance(to.Address(), usd, "1")).To(Succeed())
+		envelope := tx.Sign(from.Seed())
+		b64, err := envelope.Base64()
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err := client.SubmitTransaction(b64)
+		Expect(err).NotTo(HaveOccurred())
+		bundle, err := response.Bundle()
+		Expect(err).NotTo(HaveOccurred())
+
+		created := bundle.Created()
+		Expect(created).To(HaveLen(1))
+		account := created[0].Data.MustAccount()
+		Expect(account.AccountId.Address()).To(Equal(newAccount.Address()))
+
+		var line xdr.LedgerKey
+		var toID xdr.AccountId
+		Expect(toID.SetAddress(to.Address())).To(Succeed())
+		asset, err := usd.ToXdrObject()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(line.SetTrustline(toID, asset)).To(Succeed())
+
+		before, err := bundle.StateBefore(line, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(before.Data.MustTrustLine().Balance).To(BeEquivalentTo(10000000))
+		after, err := bundle.StateAfter(line, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(after.Data.MustTrustLine().Balance).To(BeEquivalentTo(60000000))
+		Expect(after.LastModifiedLedgerSeq).To(BeEquivalentTo(response.Ledger))
+	})
+
+	It("records removed trust lines in the meta", func() {
+		Expect(server.SetBalance(to.Address(), usd, "0")).To(Succeed())
+		envelope := build.Transaction(
+			build.SourceAccount{AddressOrSeed: to.Address()},
+			build.Sequence{Sequence: 301},
+			build.TestNetwork,
+			build.RemoveTrust("USD", issuer.Address()),
+		).Sign(to.Seed())
+		b64, err := envelope.Base64()
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err := client.SubmitTransaction(b64)
+		Expect(err).NotTo(HaveOccurred())
+		bundle, err := response.Bundle()
+		Expect(err).NotTo(HaveOccurred())
+
+		changes := bundle.TransactionMeta.MustOperations()[0].Changes
+		Expect(changes).To(HaveLen(4))
+		Expect(changes[0].EntryType()).To(Equal(xdr.LedgerEntryTypeAccount))
+		Expect(changes[1].MustUpdated().Data.MustAccount().NumSubEntries).To(BeEquivalentTo(0))
+		Expect(changes[3].Type).To(Equal(xdr.LedgerEntryChangeTypeLedgerEntryRemoved))
+	})
+
+	It("rejects bad sequences", func() {
+		err := submit(from, 205, payment("5"))
+		herr, ok := err.(*horizon.Error)
+		Expect(ok).To(BeTrue())
+		Expect(herr.TransactionCode()).To(Equal(xdr.TransactionResultCodeTxBadSeq))
+		Expect(server.Sequence(from.Address())).To(BeEquivalentTo(200))
+	})
+
+	It("rejects unsigned transactions", func() {
+		err := submit(random(), 1, payment("5"))
+		herr, ok := err.(*horizon.Error)
+		Expect(ok).To(BeTrue())
+		Expect(herr.ResultCodes()).To(Equal(horizon.TransactionResultCodes{
+			Transaction: "tx_no_source_account",
+		}))
+
+		tx := build.Transaction(
+			build.SourceAccount{AddressOrSeed: from.Address()},
+			build.Sequence{Sequence: 201},
+			build.TestNetwork,
+			payment("5"),
+		)
+		envelope := tx.Sign(to.Seed())
+		b64, err := envelope.Base64()
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = client.SubmitTransaction(b64)
+		herr, ok = err.(*horizon.Error)
+		Expect(ok).To(BeTrue())
+		Expect(herr.TransactionCode()).To(Equal(xdr.TransactionResultCodeTxBadAuth))
+	})
+
+	It("fails transactions with failing operations atomically", func() {
+		Expect(server.SetBalance(to.Address(), usd, "0")).To(Succeed())
+
+		err := submit(from, 201, payment("30"), payment("30"))
+		herr, ok := err.(*horizon.Error)
+		Expect(ok).To(BeTrue())
+		Expect(herr.ResultCodes()).To(Equal(horizon.TransactionResultCodes{
+			Transaction: "tx_failed",
+			Operations:  []string{"op_success", "op_underfunded"},
+		}))
+
+		codes, err := herr.OperationCodes()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(codes[1]).To(Equal(xdr.PaymentResultCodePaymentUnderfunded))
+
+		Expect(server.Balance(from.Address(), usd)).To(Equal("50.0000000"))
+		Expect(server.Balance(to.Address(), usd)).To(Equal("0.0000000"))
+		Expect(server.Sequence(from.Address())).To(BeEquivalentTo(201))
+	})
+
+	It("requires trust lines", func() {
+		err := submit(from, 201, payment("5"))
+		herr, ok := err.(*horizon.Error)
+		Expect(ok).To(BeTrue())
+		Expect(herr.ResultCodes()).To(Equal(horizon.TransactionResultCodes{
+			Transaction: "tx_failed",
+			Operations:  []string{"op_no_trust"},
+		}))
+	})
+
+	It("issues and burns credit assets", func() {
+		Expect(server.SetBalance(to.Address(), usd, "0")).To(Succeed())
+
+		Expect(submit(issuer, 101, payment("100"))).To(Succeed())
+		Expect(server.Balance(to.Address(), usd)).To(Equal("100.0000000"))
+
+		Expect(submit(to, 301, build.Payment(
+			build.Destination{AddressOrSeed: issuer.Address()},
+			build.CreditAmount{Code: "USD", Issuer: issuer.Address(), Amount: "40"},
+		))).To(Succeed())
+		Expect(server.Balance(to.Address(), usd)).To(Equal("60.0000000"))
+	})
+
+	It("rejects unsupported operations", func() {
+		err := submit(from, 201, build.Inflation())
+		herr, ok := err.(*horizon.Error)
+		Expect(ok).To(BeTrue())
+		Expect(herr.Problem.Type).To(Equal("transaction_malformed"))
+	})
+
+	It("works with the txsub submitter", func() {
+		Expect(server.SetBalance(to.Address(), usd, "0")).To(Succeed())
+		submitter := txsub.NewSubmitter(client)
+
+		for i := 0; i < 3; i++ {
+			result := submitter.Submit(context.Background(), from.Address(), func(p build.SequenceProvider) *build.TransactionEnvelopeBuilder {
+				tx := build.Transaction(
+					build.SourceAccount{AddressOrSeed: from.Address()},
+					build.AutoSequence{SequenceProvider: p},
+					build.TestNetwork,
+					payment("1"),
+				)
+				envelope := tx.Sign(from.Seed())
+				return &envelope
+			})
+			Expect(result.Err).NotTo(HaveOccurred())
+			Expect(result.Status).To(Equal(txsub.StatusSuccess))
+		}
+
+		Expect(server.Balance(to.Address(), usd)).To(Equal("3.0000000"))
+	})
+})
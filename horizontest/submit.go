@@ -0,0 +1,215 @@
+package horizontest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/hash"
+	"bitbucket.org/atticlab/go-smart-base/horizon"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// submit applies the transaction posted in the tx form value and answers
+// like horizon does
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	envelope := r.PostFormValue("tx")
+
+	var e xdr.TransactionEnvelope
+	err := xdr.SafeUnmarshalBase64(envelope, &e)
+	if err != nil {
+		writeMalformed(w, envelope, err)
+		return
+	}
+
+	txHash, err := build.EnvelopeFromXDR(envelope, s.Network).Transaction().Hash()
+	if err != nil {
+		writeMalformed(w, envelope, err)
+		return
+	}
+
+	result, meta, err := s.apply(&e, txHash)
+	if err != nil {
+		writeMalformed(w, envelope, err)
+		return
+	}
+
+	resultXDR, err := xdr.MarshalBase64(result)
+	if err != nil {
+		writeMalformed(w, envelope, err)
+		return
+	}
+
+	if result.Result.Code != xdr.TransactionResultCodeTxSuccess {
+		writeProblem(w, horizon.Problem{
+			Type:   "transaction_failed",
+			Title:  "Transaction Failed",
+			Status: http.StatusBadRequest,
+			Detail: "The transaction failed when submitted to the stellar network.",
+			Extras: map[string]interface{}{
+				"envelope_xdr": envelope,
+				"result_xdr":   resultXDR,
+				"result_codes": resultCodes(result),
+			},
+		})
+		return
+	}
+
+	metaXDR, err := xdr.MarshalBase64(meta)
+	if err != nil {
+		writeMalformed(w, envelope, err)
+		return
+	}
+
+	s.ledger++
+	hexHash := hex.EncodeToString(txHash[:])
+
+	var tx horizon.Transaction
+	tx.Links.Self.Href = s.URL + "/transactions/" + hexHash
+	tx.Links.Account.Href = s.URL + "/accounts/" + e.Tx.SourceAccount.Address()
+	tx.ID = hexHash
+	tx.PT = fmt.Sprint(int64(s.ledger) << 32)
+	tx.Hash = hexHash
+	tx.Ledger = s.ledger
+	tx.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+	tx.SourceAccount = e.Tx.SourceAccount.Address()
+	tx.SourceAccountSequence = fmt.Sprint(e.Tx.SeqNum)
+	tx.OperationCount = int32(len(e.Tx.Operations))
+	tx.Env = envelope
+	tx.Result = resultXDR
+	tx.Meta = metaXDR
+	tx.MemoType = "none"
+	for _, sig := range e.Signatures {
+		tx.Signatures = append(tx.Signatures, base64.StdEncoding.EncodeToString(sig.Signature))
+	}
+	s.transactions[hexHash] = tx
+
+	var response horizon.TransactionSuccess
+	response.Links.Transaction = tx.Links.Self
+	response.Hash = hexHash
+	response.Ledger = s.ledger
+	response.Env = envelope
+	response.Result = resultXDR
+	response.Meta = metaXDR
+	writeJSON(w, http.StatusOK, response)
+}
+
+// latestLedger returns the last ledger closed by the server, including its
+// XDR header
+func (s *Server) latestLedger() (ret horizon.Ledger, err error) {
+	header := xdr.LedgerHeader{
+		LedgerVersion: 1,
+		LedgerSeq:     xdr.Uint32(s.ledger),
+		BaseFee:       xdr.Uint32(s.BaseFee),
+		MaxTxSetSize:  500,
+	}
+
+	var raw bytes.Buffer
+	_, err = xdr.Marshal(&raw, &header)
+	if err != nil {
+		return
+	}
+	h := hash.Hash(raw.Bytes())
+
+	ret.ID = hex.EncodeToString(h[:])
+	ret.PT = fmt.Sprint(int64(s.ledger) << 32)
+	ret.Hash = ret.ID
+	ret.Sequence = s.ledger
+	ret.ClosedAt = time.Now().UTC().Format(time.RFC3339)
+	ret.TotalCoins = amount.String(0)
+	ret.FeePool = amount.String(0)
+	ret.BaseFee = s.BaseFee
+	ret.BaseReserve = amount.String(0)
+	ret.MaxTxSetSize = 500
+	ret.HeaderXDR = base64.StdEncoding.EncodeToString(raw.Bytes())
+	return
+}
+
+func writeMalformed(w http.ResponseWriter, envelope string, err error) {
+	writeProblem(w, horizon.Problem{
+		Type:   "transaction_malformed",
+		Title:  "Transaction Malformed",
+		Status: http.StatusBadRequest,
+		Detail: err.Error(),
+		Extras: map[string]interface{}{
+			"envelope_xdr": envelope,
+		},
+	})
+}
+
+// resultCodes returns the result codes of a failed transaction, as reported
+// in the extras of horizon problems
+func resultCodes(result xdr.TransactionResult) horizon.TransactionResultCodes {
+	codes := horizon.TransactionResultCodes{
+		Transaction: transactionCodes[result.Result.Code],
+	}
+
+	if result.Result.Code != xdr.TransactionResultCodeTxFailed {
+		return codes
+	}
+
+	for _, op := range result.Result.MustResults() {
+		codes.Operations = append(codes.Operations, operationCode(op))
+	}
+	return codes
+}
+
+var transactionCodes = map[xdr.TransactionResultCode]string{
+	xdr.TransactionResultCodeTxSuccess:          "tx_success",
+	xdr.TransactionResultCodeTxFailed:           "tx_failed",
+	xdr.TransactionResultCodeTxMissingOperation: "tx_missing_operation",
+	xdr.TransactionResultCodeTxBadSeq:           "tx_bad_seq",
+	xdr.TransactionResultCodeTxBadAuth:          "tx_bad_auth",
+	xdr.TransactionResultCodeTxNoAccount:        "tx_no_source_account",
+}
+
+func operationCode(op xdr.OperationResult) string {
+	switch op.Code {
+	case xdr.OperationResultCodeOpBadAuth:
+		return "op_bad_auth"
+	case xdr.OperationResultCodeOpNoAccount:
+		return "op_no_source_account"
+	}
+
+	switch op.Tr.Type {
+	case xdr.OperationTypeCreateAccount:
+		return createAccountCodes[op.Tr.CreateAccountResult.Code]
+	case xdr.OperationTypePayment:
+		return paymentCodes[op.Tr.PaymentResult.Code]
+	case xdr.OperationTypeChangeTrust:
+		return changeTrustCodes[op.Tr.ChangeTrustResult.Code]
+	}
+	return ""
+}
+
+var createAccountCodes = map[xdr.CreateAccountResultCode]string{
+	xdr.CreateAccountResultCodeCreateAccountSuccess:      "op_success",
+	xdr.CreateAccountResultCodeCreateAccountMalformed:    "op_malformed",
+	xdr.CreateAccountResultCodeCreateAccountUnderfunded:  "op_underfunded",
+	xdr.CreateAccountResultCodeCreateAccountAlreadyExist: "op_already_exists",
+	xdr.CreateAccountResultCodeCreateAccountLineFull:     "op_line_full",
+	xdr.CreateAccountResultCodeCreateAccountNoIssuer:     "op_no_issuer",
+}
+
+var paymentCodes = map[xdr.PaymentResultCode]string{
+	xdr.PaymentResultCodePaymentSuccess:       "op_success",
+	xdr.PaymentResultCodePaymentMalformed:     "op_malformed",
+	xdr.PaymentResultCodePaymentUnderfunded:   "op_underfunded",
+	xdr.PaymentResultCodePaymentSrcNoTrust:    "op_src_no_trust",
+	xdr.PaymentResultCodePaymentNoDestination: "op_no_destination",
+	xdr.PaymentResultCodePaymentNoTrust:       "op_no_trust",
+	xdr.PaymentResultCodePaymentLineFull:      "op_line_full",
+	xdr.PaymentResultCodePaymentNoIssuer:      "op_no_issuer",
+}
+
+var changeTrustCodes = map[xdr.ChangeTrustResultCode]string{
+	xdr.ChangeTrustResultCodeChangeTrustSuccess:      "op_success",
+	xdr.ChangeTrustResultCodeChangeTrustMalformed:    "op_malformed",
+	xdr.ChangeTrustResultCodeChangeTrustNoIssuer:     "op_no_issuer",
+	xdr.ChangeTrustResultCodeChangeTrustInvalidLimit: "op_invalid_limit",
+}
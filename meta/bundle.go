@@ -12,6 +12,20 @@ import (
 // be found.
 var ErrMetaNotFound = errors.New("meta: no changes found")
 
+// Created returns the ledger entries created by the application of the
+// transaction that produced `b`, in the order they were created.
+func (b *Bundle) Created() (ret []xdr.LedgerEntry) {
+	for _, op := range b.TransactionMeta.MustOperations() {
+		for _, change := range op.Changes {
+			if change.Type == xdr.LedgerEntryChangeTypeLedgerEntryCreated {
+				ret = append(ret, change.MustCreated())
+			}
+		}
+	}
+
+	return
+}
+
 // InitialState returns the initial state of the LedgerEntry identified by `key`
 // just prior to the application of the transaction the produced `b`.  Returns
 // nil if the ledger entry did not exist prior to the bundle.
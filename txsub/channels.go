@@ -0,0 +1,139 @@
+package txsub
+
+import (
+	"context"
+	"errors"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/keypair"
+)
+
+// Channel is a channel account leased from a ChannelPool
+type Channel struct {
+	Seed    string
+	Address string
+}
+
+// ChannelPool submits the operations of a distribution agent through a pool
+// of channel accounts.  Each transaction uses a channel as its source, so
+// that it consumes the channel's sequence instead of the agent's, while its
+// operations keep the agent as their source.  Transactions are signed by
+// both the channel and the agent, and concurrent submissions use distinct
+// channels.  It is safe for concurrent use.
+type ChannelPool struct {
+	// Agent is the seed of the account the operations are made on behalf of
+	Agent     string
+	Network   build.Network
+	Submitter *Submitter
+
+	channels chan *Channel
+}
+
+// NewChannelPool returns a pool submitting the operations of the agent
+// through the channel accounts whose seeds are provided.
+func NewChannelPool(
+	agent string,
+	channels []string,
+	network build.Network,
+	submitter *Submitter,
+) (*ChannelPool, error) {
+
+	if len(channels) == 0 {
+		return nil, errors.New("no channel accounts")
+	}
+
+	if _, err := keypair.Parse(agent); err != nil {
+		return nil, err
+	}
+
+	p := &ChannelPool{
+		Agent:     agent,
+		Network:   network,
+		Submitter: submitter,
+		channels:  make(chan *Channel, len(channels)),
+	}
+
+	for _, seed := range channels {
+		kp, err := keypair.Parse(seed)
+		if err != nil {
+			return nil, err
+		}
+
+		p.channels <- &Channel{Seed: seed, Address: kp.Address()}
+	}
+
+	return p, nil
+}
+
+// Lease waits until a channel is available and leases it.  The channel must
+// be returned to the pool using Release.
+func (p *ChannelPool) Lease(ctx context.Context) (*Channel, error) {
+	select {
+	case c := <-p.channels:
+		return c, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Release returns a leased channel to the pool
+func (p *ChannelPool) Release(c *Channel) {
+	p.channels <- c
+}
+
+// Build builds a transaction with the provided mutators, using the channel
+// as transaction source and the agent as source of every operation that
+// does not set its own, and signs it with both keys.
+func (p *ChannelPool) Build(
+	c *Channel,
+	provider build.SequenceProvider,
+	muts ...build.TransactionMutator,
+) *build.TransactionEnvelopeBuilder {
+
+	all := []build.TransactionMutator{
+		build.SourceAccount{AddressOrSeed: c.Seed},
+		build.AutoSequence{SequenceProvider: provider},
+		p.Network,
+	}
+	all = append(all, muts...)
+	all = append(all, operationSource{AddressOrSeed: p.Agent})
+
+	tx := build.Transaction(all...)
+	b := tx.Sign(c.Seed, p.Agent)
+	return &b
+}
+
+// Submit leases a channel, submits the transaction built with the provided
+// mutators through it and waits for its final status.
+func (p *ChannelPool) Submit(ctx context.Context, muts ...build.TransactionMutator) Result {
+	c, err := p.Lease(ctx)
+	if err != nil {
+		return Result{Status: StatusFailed, Err: err}
+	}
+	defer p.Release(c)
+
+	return p.Submitter.Submit(ctx, c.Address, func(provider build.SequenceProvider) *build.TransactionEnvelopeBuilder {
+		return p.Build(c, provider, muts...)
+	})
+}
+
+// operationSource sets the source of the operations that do not have one
+type operationSource struct {
+	AddressOrSeed string
+}
+
+func (m operationSource) MutateTransaction(o *build.TransactionBuilder) error {
+	for i := range o.TX.Operations {
+		op := &o.TX.Operations[i]
+		if op.SourceAccount != nil {
+			continue
+		}
+
+		err := build.SourceAccount{AddressOrSeed: m.AddressOrSeed}.MutateOperation(op)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package txsub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/keypair"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChannelPool", func() {
+	var (
+		fake     *fakeHorizon
+		channels []*keypair.Full
+		subject  *ChannelPool
+	)
+
+	payment := build.Payment(
+		build.Destination{AddressOrSeed: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"},
+		build.NativeAmount{Amount: "10"},
+	)
+
+	BeforeEach(func() {
+		fake = &fakeHorizon{applied: map[string]bool{}}
+		mock := &build.MockSequenceProvider{Data: map[string]xdr.SequenceNumber{}}
+
+		channels = nil
+		var seeds []string
+		for i := 0; i < 2; i++ {
+			kp, err := keypair.Random()
+			Expect(err).NotTo(HaveOccurred())
+			channels = append(channels, kp)
+			seeds = append(seeds, kp.Seed())
+			mock.Data[kp.Address()] = 10
+		}
+
+		submitter := &Submitter{Horizon: fake, Sequences: NewSequenceManager(mock)}
+
+		var err error
+		subject, err = NewChannelPool(seed, seeds, build.TestNetwork, submitter)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects invalid keys", func() {
+		_, err := NewChannelPool(seed, nil, build.TestNetwork, nil)
+		Expect(err).To(HaveOccurred())
+		_, err = NewChannelPool(seed, []string{"bad"}, build.TestNetwork, nil)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("uses channels as transaction sources and the agent as operation source", func() {
+		result := subject.Submit(context.Background(), payment)
+		Expect(result.Status).To(Equal(StatusSuccess))
+
+		Expect(fake.envelopes).To(HaveLen(1))
+		e := fake.envelopes[0]
+		Expect(e.Tx.SourceAccount.Address()).To(Equal(channels[0].Address()))
+		Expect(e.Tx.Operations[0].SourceAccount.Address()).To(Equal(address))
+		Expect(e.Signatures).To(HaveLen(2))
+	})
+
+	It("leases distinct channels to concurrent submissions", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				Expect(subject.Submit(context.Background(), payment).Status).To(Equal(StatusSuccess))
+			}()
+		}
+		wg.Wait()
+
+		sequences := map[string][]xdr.SequenceNumber{}
+		for _, e := range fake.envelopes {
+			source := e.Tx.SourceAccount.Address()
+			sequences[source] = append(sequences[source], e.Tx.SeqNum)
+		}
+
+		var total int
+		for _, seqs := range sequences {
+			for i, seq := range seqs {
+				Expect(seq).To(BeEquivalentTo(11 + i))
+			}
+			total += len(seqs)
+		}
+		Expect(total).To(Equal(6))
+	})
+
+	It("waits for a channel to be released", func() {
+		first, _ := subject.Lease(context.Background())
+		subject.Lease(context.Background())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err := subject.Lease(ctx)
+		Expect(err).To(Equal(context.DeadlineExceeded))
+
+		subject.Release(first)
+		c, err := subject.Lease(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c).To(Equal(first))
+	})
+})
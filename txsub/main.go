@@ -0,0 +1,238 @@
+// Package txsub submits transactions to horizon and waits for their final
+// status.
+//
+// A Submitter queues the transactions of each source account and submits
+// them one at a time, building them with sequences reserved from a
+// SequenceManager.  Transactions rejected with tx_bad_seq are rebuilt with a
+// resynced sequence, and submissions that time out are confirmed by looking
+// up the transaction hash before resubmitting the same envelope.
+package txsub
+
+import (
+	"context"
+	"sync"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/horizon"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// DefaultMaxAttempts is the number of submissions attempted for a
+// transaction when the Submitter does not specify one.
+const DefaultMaxAttempts = 3
+
+// Status is the final status of a submitted transaction
+type Status int
+
+const (
+	// StatusSuccess means the transaction was applied successfully
+	StatusSuccess Status = iota
+	// StatusFailed means the transaction was rejected or failed, and will not
+	// be applied
+	StatusFailed
+	// StatusUnknown means the transaction could not be confirmed and might
+	// still be applied
+	StatusUnknown
+)
+
+// Horizon is the part of *horizon.Client used by the Submitter
+type Horizon interface {
+	SubmitTransactionContext(ctx context.Context, envelope string) (horizon.TransactionSuccess, error)
+	LoadTransactionContext(ctx context.Context, hash string) (horizon.Transaction, error)
+}
+
+var _ Horizon = &horizon.Client{}
+
+// BuildFunc builds and signs a transaction for submission.  It is called
+// again with the same provider when the transaction must be rebuilt with a
+// new sequence, so it should get its sequence using
+// build.AutoSequence{provider}.
+type BuildFunc func(provider build.SequenceProvider) *build.TransactionEnvelopeBuilder
+
+// Result is the outcome of a submission
+type Result struct {
+	Status   Status
+	Hash     string
+	Response horizon.TransactionSuccess
+	Attempts int
+	Err      error
+}
+
+// Submitter submits transactions, serializing the submissions of each source
+// account.  It is safe for concurrent use.
+type Submitter struct {
+	Horizon     Horizon
+	Sequences   *SequenceManager
+	MaxAttempts int
+
+	lock   sync.Mutex
+	queues map[string]*queue
+}
+
+// NewSubmitter returns a submitter using the provided horizon client both to
+// submit transactions and to load sequences.
+func NewSubmitter(client *horizon.Client) *Submitter {
+	return &Submitter{
+		Horizon:   client,
+		Sequences: NewSequenceManager(client),
+	}
+}
+
+type job struct {
+	ctx    context.Context
+	build  BuildFunc
+	result chan Result
+}
+
+type queue struct {
+	jobs []*job
+}
+
+// Submit submits the transaction built by fn for the source account and
+// waits for its final status.
+func (s *Submitter) Submit(ctx context.Context, source string, fn BuildFunc) Result {
+	select {
+	case r := <-s.SubmitAsync(ctx, source, fn):
+		return r
+	case <-ctx.Done():
+		return Result{Status: StatusUnknown, Err: ctx.Err()}
+	}
+}
+
+// SubmitAsync queues the transaction built by fn for the source account.
+// Transactions of the same account are submitted in the order they were
+// queued.  The returned channel receives the final status.
+func (s *Submitter) SubmitAsync(ctx context.Context, source string, fn BuildFunc) <-chan Result {
+	j := &job{ctx: ctx, build: fn, result: make(chan Result, 1)}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.queues == nil {
+		s.queues = map[string]*queue{}
+	}
+
+	q, running := s.queues[source]
+	if !running {
+		q = &queue{}
+		s.queues[source] = q
+	}
+	q.jobs = append(q.jobs, j)
+
+	if !running {
+		go s.work(source, q)
+	}
+
+	return j.result
+}
+
+// work submits the jobs of the queue until it is empty
+func (s *Submitter) work(source string, q *queue) {
+	for {
+		s.lock.Lock()
+		if len(q.jobs) == 0 {
+			delete(s.queues, source)
+			s.lock.Unlock()
+			return
+		}
+
+		j := q.jobs[0]
+		q.jobs = q.jobs[1:]
+		s.lock.Unlock()
+
+		j.result <- s.submit(j.ctx, source, j.build)
+	}
+}
+
+// submit builds and submits a transaction until its final status is known
+func (s *Submitter) submit(ctx context.Context, source string, fn BuildFunc) (result Result) {
+	maxAttempts := s.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	var envelope string
+
+	for result.Attempts < maxAttempts {
+		if err := ctx.Err(); err != nil {
+			result.Status, result.Err = StatusUnknown, err
+			if envelope == "" {
+				result.Status = StatusFailed
+			}
+			return
+		}
+
+		// (re)build the transaction, unless the envelope must be resubmitted
+		if envelope == "" {
+			b := fn(s.Sequences)
+			var err error
+			envelope, err = b.Base64()
+			if err == nil {
+				result.Hash, err = b.Transaction().HashHex()
+			}
+			if err != nil {
+				s.Sequences.Resync(source)
+				result.Status, result.Err = StatusFailed, err
+				return
+			}
+		}
+
+		result.Attempts++
+		resp, err := s.Horizon.SubmitTransactionContext(ctx, envelope)
+		if err == nil {
+			result.Status, result.Response, result.Err = StatusSuccess, resp, nil
+			return
+		}
+		result.Err = err
+
+		herr, ok := err.(*horizon.Error)
+		switch {
+		case ok && herr.Problem.Status >= 400 && herr.Problem.Status < 500:
+			s.Sequences.Resync(source)
+			if badSeq(herr) {
+				envelope = ""
+				continue
+			}
+
+			result.Status = StatusFailed
+			return
+		default:
+			// the submission timed out or failed in transit: it might have been
+			// applied, so only resubmit the same envelope if it was not
+			tx, err := s.Horizon.LoadTransactionContext(ctx, result.Hash)
+			if err == nil {
+				result.Status, result.Response, result.Err = StatusSuccess, success(tx), nil
+				return
+			}
+		}
+	}
+
+	result.Status = StatusUnknown
+	if envelope == "" {
+		// the last attempt was rejected with tx_bad_seq
+		result.Status = StatusFailed
+	}
+	return
+}
+
+// badSeq returns true if the transaction was rejected because of its sequence
+func badSeq(herr *horizon.Error) bool {
+	if code, err := herr.TransactionCode(); err == nil {
+		return code == xdr.TransactionResultCodeTxBadSeq
+	}
+
+	codes, err := herr.ResultCodes()
+	return err == nil && codes.Transaction == "tx_bad_seq"
+}
+
+// success converts a transaction loaded from horizon into the response of
+// its submission
+func success(tx horizon.Transaction) (ret horizon.TransactionSuccess) {
+	ret.Links.Transaction = tx.Links.Self
+	ret.Hash = tx.Hash
+	ret.Ledger = tx.Ledger
+	ret.Env = tx.Env
+	ret.Result = tx.Result
+	ret.Meta = tx.Meta
+	return
+}
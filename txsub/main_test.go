@@ -0,0 +1,228 @@
+package txsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/horizon"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestTxsub(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Package: bitbucket.org/atticlab/go-smart-base/txsub")
+}
+
+const (
+	address = "GA3FR7TVTDJAY6TN4MUX7BF4KK6SUHWIYDY7NRNUDTA4OVY3IMY7B6H5"
+	seed    = "SDOTALIMPAM2IV65IOZA7KZL7XWZI5BODFXTRVLIHLQZQCKK57PH5F3H"
+)
+
+var _ = Describe("SequenceManager", func() {
+	var (
+		mock    *build.MockSequenceProvider
+		subject *SequenceManager
+	)
+
+	BeforeEach(func() {
+		mock = &build.MockSequenceProvider{Data: map[string]xdr.SequenceNumber{address: 10}}
+		subject = NewSequenceManager(mock)
+	})
+
+	It("reserves consecutive sequences", func() {
+		for _, expected := range []xdr.SequenceNumber{10, 11, 12} {
+			seq, err := subject.SequenceForAccount(address)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(seq).To(Equal(expected))
+		}
+	})
+
+	It("reloads the sequence after a resync", func() {
+		subject.SequenceForAccount(address)
+		mock.Data[address] = 20
+		subject.Resync(address)
+
+		seq, err := subject.SequenceForAccount(address)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(seq).To(BeEquivalentTo(20))
+	})
+
+	It("propagates provider errors", func() {
+		_, err := subject.SequenceForAccount("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("is safe for concurrent use", func() {
+		var (
+			wg   sync.WaitGroup
+			lock sync.Mutex
+			seen = map[xdr.SequenceNumber]bool{}
+		)
+
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				seq, _ := subject.SequenceForAccount(address)
+				lock.Lock()
+				seen[seq] = true
+				lock.Unlock()
+			}()
+		}
+		wg.Wait()
+
+		Expect(seen).To(HaveLen(50))
+	})
+})
+
+var _ = Describe("Submitter", func() {
+	var (
+		fake    *fakeHorizon
+		mock    *build.MockSequenceProvider
+		subject *Submitter
+	)
+
+	buildPayment := func(provider build.SequenceProvider) *build.TransactionEnvelopeBuilder {
+		b := &build.TransactionEnvelopeBuilder{}
+		b.MutateTX(
+			build.SourceAccount{AddressOrSeed: seed},
+			build.AutoSequence{SequenceProvider: provider},
+			build.TestNetwork,
+			build.Payment(
+				build.Destination{AddressOrSeed: "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"},
+				build.NativeAmount{Amount: "10"},
+			),
+		)
+		b.Mutate(build.Sign{Seed: seed})
+		return b
+	}
+
+	BeforeEach(func() {
+		fake = &fakeHorizon{applied: map[string]bool{}}
+		mock = &build.MockSequenceProvider{Data: map[string]xdr.SequenceNumber{address: 10}}
+		subject = &Submitter{Horizon: fake, Sequences: NewSequenceManager(mock)}
+	})
+
+	It("submits transactions with consecutive sequences", func() {
+		first := subject.SubmitAsync(context.Background(), address, buildPayment)
+		second := subject.SubmitAsync(context.Background(), address, buildPayment)
+
+		Expect((<-first).Status).To(Equal(StatusSuccess))
+		Expect((<-second).Status).To(Equal(StatusSuccess))
+		Expect(fake.sequences).To(Equal([]xdr.SequenceNumber{11, 12}))
+	})
+
+	It("rebuilds transactions rejected with tx_bad_seq", func() {
+		// the cached sequence is stale once another client used the account
+		subject.Sequences.SequenceForAccount(address)
+		mock.Data[address] = 15
+		fake.errs = []error{badSeqError()}
+
+		result := subject.Submit(context.Background(), address, buildPayment)
+		Expect(result.Status).To(Equal(StatusSuccess))
+		Expect(result.Attempts).To(Equal(2))
+		Expect(fake.sequences).To(Equal([]xdr.SequenceNumber{12, 16}))
+	})
+
+	It("fails on other rejections", func() {
+		fake.errs = []error{&horizon.Error{Problem: horizon.Problem{Status: 400}}}
+
+		result := subject.Submit(context.Background(), address, buildPayment)
+		Expect(result.Status).To(Equal(StatusFailed))
+		Expect(result.Err).To(HaveOccurred())
+	})
+
+	It("confirms timed out submissions by hash", func() {
+		fake.errs = []error{errors.New("timeout")}
+		fake.applyOnError = true
+
+		result := subject.Submit(context.Background(), address, buildPayment)
+		Expect(result.Status).To(Equal(StatusSuccess))
+		Expect(result.Response.Hash).To(Equal(result.Hash))
+		Expect(fake.sequences).To(HaveLen(1))
+	})
+
+	It("confirms submissions failing without a client error status", func() {
+		fake.errs = []error{&horizon.Error{Problem: horizon.Problem{Title: "Bad Gateway"}}}
+		fake.applyOnError = true
+
+		result := subject.Submit(context.Background(), address, buildPayment)
+		Expect(result.Status).To(Equal(StatusSuccess))
+		Expect(fake.sequences).To(HaveLen(1))
+	})
+
+	It("resubmits the same envelope when the transaction was not applied", func() {
+		fake.errs = []error{&horizon.Error{Problem: horizon.Problem{Status: 504}}}
+
+		result := subject.Submit(context.Background(), address, buildPayment)
+		Expect(result.Status).To(Equal(StatusSuccess))
+		Expect(fake.sequences).To(Equal([]xdr.SequenceNumber{11, 11}))
+	})
+
+	It("reports an unknown status when attempts are exhausted", func() {
+		fake.errs = []error{errors.New("timeout"), errors.New("timeout"), errors.New("timeout")}
+
+		result := subject.Submit(context.Background(), address, buildPayment)
+		Expect(result.Status).To(Equal(StatusUnknown))
+		Expect(result.Attempts).To(Equal(DefaultMaxAttempts))
+	})
+})
+
+// fakeHorizon fails the submissions with the queued errors, then applies
+// them.
+type fakeHorizon struct {
+	lock         sync.Mutex
+	errs         []error
+	applyOnError bool
+	applied      map[string]bool
+	sequences    []xdr.SequenceNumber
+	envelopes    []xdr.TransactionEnvelope
+}
+
+func (f *fakeHorizon) SubmitTransactionContext(ctx context.Context, envelope string) (horizon.TransactionSuccess, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	b := build.EnvelopeFromXDR(envelope, build.TestNetwork)
+	hash, err := b.Transaction().HashHex()
+	if err != nil {
+		return horizon.TransactionSuccess{}, err
+	}
+	f.sequences = append(f.sequences, b.E.Tx.SeqNum)
+	f.envelopes = append(f.envelopes, *b.E)
+
+	if len(f.errs) > 0 {
+		err, f.errs = f.errs[0], f.errs[1:]
+		if f.applyOnError {
+			f.applied[hash] = true
+		}
+		return horizon.TransactionSuccess{}, err
+	}
+
+	f.applied[hash] = true
+	return horizon.TransactionSuccess{Hash: hash}, nil
+}
+
+func (f *fakeHorizon) LoadTransactionContext(ctx context.Context, hash string) (horizon.Transaction, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if !f.applied[hash] {
+		return horizon.Transaction{}, &horizon.Error{Problem: horizon.Problem{Status: 404}}
+	}
+	return horizon.Transaction{Hash: hash}, nil
+}
+
+func badSeqError() error {
+	return &horizon.Error{Problem: horizon.Problem{
+		Status: 400,
+		Extras: map[string]interface{}{
+			"result_codes": map[string]interface{}{"transaction": "tx_bad_seq"},
+		},
+	}}
+}
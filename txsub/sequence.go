@@ -0,0 +1,63 @@
+package txsub
+
+import (
+	"sync"
+
+	"bitbucket.org/atticlab/go-smart-base/build"
+	"bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+// SequenceManager is a build.SequenceProvider that caches the sequence of
+// each account, so that consecutive transactions built with
+// build.AutoSequence get consecutive sequences without reloading the account.
+// It is safe for concurrent use.
+type SequenceManager struct {
+	// Provider loads the current sequence of accounts missing from the cache,
+	// usually a *horizon.Client.
+	Provider build.SequenceProvider
+
+	lock      sync.Mutex
+	sequences map[string]xdr.SequenceNumber
+}
+
+var _ build.SequenceProvider = &SequenceManager{}
+
+// NewSequenceManager returns a sequence manager loading sequences from the
+// provided provider.
+func NewSequenceManager(provider build.SequenceProvider) *SequenceManager {
+	return &SequenceManager{Provider: provider}
+}
+
+// SequenceForAccount implements build.SequenceProvider.  Every call reserves
+// a sequence: the first call returns the account's current sequence and each
+// following call returns the previous result plus one.
+func (m *SequenceManager) SequenceForAccount(accountID string) (xdr.SequenceNumber, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.sequences == nil {
+		m.sequences = map[string]xdr.SequenceNumber{}
+	}
+
+	seq, ok := m.sequences[accountID]
+	if !ok {
+		var err error
+		seq, err = m.Provider.SequenceForAccount(accountID)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	m.sequences[accountID] = seq + 1
+	return seq, nil
+}
+
+// Resync drops the cached sequence of the account, so that it is reloaded
+// from the provider on next use.  Call it once a transaction using a
+// reserved sequence could not be applied, or failed with tx_bad_seq.
+func (m *SequenceManager) Resync(accountID string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.sequences, accountID)
+}
@@ -1,11 +1,13 @@
 package xdr
 
 import (
+	"database/sql/driver"
 	"errors"
 	"fmt"
 )
 
-// This file contains implementations of the sql.Scanner interface for stellar xdr types
+// This file contains implementations of the sql.Scanner and driver.Valuer
+// interfaces for stellar xdr types
 
 // Scan reads from src into an AccountFlags
 func (t *AccountFlags) Scan(src interface{}) error {
@@ -18,6 +20,21 @@ func (t *AccountFlags) Scan(src interface{}) error {
 	return nil
 }
 
+// Value returns an AccountFlags as an int64
+func (t AccountFlags) Value() (driver.Value, error) {
+	return int64(t), nil
+}
+
+// Scan reads from src into an Asset struct
+func (t *Asset) Scan(src interface{}) error {
+	return safeBase64Scan(src, t)
+}
+
+// Value encodes an Asset struct as base64
+func (t Asset) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an AssetType
 func (t *AssetType) Scan(src interface{}) error {
 	val, ok := src.(int64)
@@ -29,6 +46,11 @@ func (t *AssetType) Scan(src interface{}) error {
 	return nil
 }
 
+// Value returns an AssetType as an int64
+func (t AssetType) Value() (driver.Value, error) {
+	return int64(t), nil
+}
+
 // Scan reads from src into an Int64
 func (t *Int64) Scan(src interface{}) error {
 	val, ok := src.(int64)
@@ -40,51 +62,131 @@ func (t *Int64) Scan(src interface{}) error {
 	return nil
 }
 
+// Value returns an Int64 as an int64
+func (t Int64) Value() (driver.Value, error) {
+	return int64(t), nil
+}
+
 // Scan reads from src into an LedgerEntryChanges struct
 func (t *LedgerEntryChanges) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an LedgerEntryChanges struct as base64
+func (t LedgerEntryChanges) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an LedgerHeader struct
 func (t *LedgerHeader) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an LedgerHeader struct as base64
+func (t LedgerHeader) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
+// Scan reads from src into an LedgerKey struct
+func (t *LedgerKey) Scan(src interface{}) error {
+	return safeBase64Scan(src, t)
+}
+
+// Value encodes an LedgerKey struct as base64
+func (t LedgerKey) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
+// Scan reads from src into an OperationFee struct
+func (t *OperationFee) Scan(src interface{}) error {
+	return safeBase64Scan(src, t)
+}
+
+// Value encodes an OperationFee struct as base64
+func (t OperationFee) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
+// Scan reads from src into an ReversedPaymentEntry struct
+func (t *ReversedPaymentEntry) Scan(src interface{}) error {
+	return safeBase64Scan(src, t)
+}
+
+// Value encodes an ReversedPaymentEntry struct as base64
+func (t ReversedPaymentEntry) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an ScpEnvelope struct
 func (t *ScpEnvelope) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an ScpEnvelope struct as base64
+func (t ScpEnvelope) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an ScpEnvelope struct
 func (t *ScpQuorumSet) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an ScpQuorumSet struct as base64
+func (t ScpQuorumSet) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an Thresholds struct
 func (t *Thresholds) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an Thresholds struct as base64
+func (t Thresholds) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an TransactionEnvelope struct
 func (t *TransactionEnvelope) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an TransactionEnvelope struct as base64
+func (t TransactionEnvelope) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an TransactionMeta struct
 func (t *TransactionMeta) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an TransactionMeta struct as base64
+func (t TransactionMeta) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an TransactionResult struct
 func (t *TransactionResult) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an TransactionResult struct as base64
+func (t TransactionResult) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // Scan reads from src into an TransactionResultPair struct
 func (t *TransactionResultPair) Scan(src interface{}) error {
 	return safeBase64Scan(src, t)
 }
 
+// Value encodes an TransactionResultPair struct as base64
+func (t TransactionResultPair) Value() (driver.Value, error) {
+	return MarshalBase64(t)
+}
+
 // safeBase64Scan scans from src (which should be either a []byte or string)
 // into dest by using `SafeUnmarshalBase64`.
 func safeBase64Scan(src, dest interface{}) error {
@@ -2,6 +2,10 @@ package xdr_test
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
 
 	. "bitbucket.org/atticlab/go-smart-base/xdr"
 
@@ -110,3 +114,155 @@ var _ = Describe("sql.Scanner implementations", func() {
 			"AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAA="),
 	)
 })
+
+var _ = Describe("driver.Valuer implementations", func() {
+	var db *sql.DB
+
+	BeforeEach(func() {
+		var err error
+		db, err = sql.Open("xdr-memory", "")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() { db.Close() })
+
+	// roundTrip writes value to the database then scans it back into dest
+	roundTrip := func(value driver.Valuer, dest sql.Scanner) {
+		_, err := db.Exec("INSERT", value)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(db.QueryRow("SELECT").Scan(dest)).To(Succeed())
+
+		expected, err := value.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dest.(driver.Valuer).Value()).To(Equal(expected))
+	}
+
+	var account AccountId
+	var usd Asset
+
+	BeforeEach(func() {
+		Expect(account.SetAddress("GA3FR7TVTDJAY6TN4MUX7BF4KK6SUHWIYDY7NRNUDTA4OVY3IMY7B6H5")).To(Succeed())
+		Expect(usd.SetCredit("USD", account)).To(Succeed())
+	})
+
+	It("round trips integers", func() {
+		roundTrip(AccountFlags(4), new(AccountFlags))
+		roundTrip(AssetTypeAssetTypeCreditAlphanum12, new(AssetType))
+		roundTrip(Int64(-5), new(Int64))
+
+		value, err := Int64(-5).Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(int64(-5)))
+	})
+
+	It("round trips xdr structs as base64", func() {
+		roundTrip(usd, &Asset{})
+		roundTrip(Thresholds{1, 2, 3, 4}, &Thresholds{})
+		roundTrip(LedgerKey{
+			Type:    LedgerEntryTypeAccount,
+			Account: &LedgerKeyAccount{AccountId: account},
+		}, &LedgerKey{})
+		roundTrip(ReversedPaymentEntry{Id: 42}, &ReversedPaymentEntry{})
+
+		percent := Int64(10000000)
+		roundTrip(OperationFee{
+			Type: OperationFeeTypeOpFeeCharged,
+			Fee: &OperationFeeFee{
+				Asset:          usd,
+				AmountToCharge: 1000000,
+				PercentFee:     &percent,
+			},
+		}, &OperationFee{})
+
+		roundTrip(TransactionEnvelope{
+			Tx: Transaction{
+				SourceAccount: account,
+				Fee:           100,
+				SeqNum:        2,
+				Operations: []Operation{{Body: OperationBody{
+					Type:        OperationTypeAccountMerge,
+					Destination: &account,
+				}}},
+			},
+		}, &TransactionEnvelope{})
+		roundTrip(TransactionResult{
+			Result: TransactionResultResult{Code: TransactionResultCodeTxBadSeq},
+		}, &TransactionResult{})
+	})
+
+	It("encodes base64 strings", func() {
+		value, err := Thresholds{1, 0, 0, 0}.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal("AQAAAA=="))
+	})
+})
+
+func init() {
+	sql.Register("xdr-memory", &memoryDriver{})
+}
+
+// memoryDriver is a database/sql driver storing, in memory, the arguments of
+// every statement executed.  Queries return the last stored row.
+type memoryDriver struct {
+	lock sync.Mutex
+	rows [][]driver.Value
+}
+
+type memoryConn struct{ driver *memoryDriver }
+
+type memoryStmt struct{ driver *memoryDriver }
+
+type memoryRows struct{ row []driver.Value }
+
+func (d *memoryDriver) Open(name string) (driver.Conn, error) {
+	return &memoryConn{driver: d}, nil
+}
+
+func (c *memoryConn) Prepare(query string) (driver.Stmt, error) {
+	return &memoryStmt{driver: c.driver}, nil
+}
+
+func (c *memoryConn) Close() error { return nil }
+
+func (c *memoryConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (s *memoryStmt) Close() error { return nil }
+
+func (s *memoryStmt) NumInput() int { return -1 }
+
+func (s *memoryStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.lock.Lock()
+	defer s.driver.lock.Unlock()
+	s.driver.rows = append(s.driver.rows, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *memoryStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.lock.Lock()
+	defer s.driver.lock.Unlock()
+	if len(s.driver.rows) == 0 {
+		return nil, errors.New("no rows stored")
+	}
+	return &memoryRows{row: s.driver.rows[len(s.driver.rows)-1]}, nil
+}
+
+func (r *memoryRows) Columns() []string {
+	columns := make([]string, len(r.row))
+	for i := range columns {
+		columns[i] = "value"
+	}
+	return columns
+}
+
+func (r *memoryRows) Close() error { return nil }
+
+func (r *memoryRows) Next(dest []driver.Value) error {
+	if r.row == nil {
+		return io.EOF
+	}
+	copy(dest, r.row)
+	r.row = nil
+	return nil
+}
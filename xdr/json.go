@@ -0,0 +1,691 @@
+package xdr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MarshalJSON encodes the provided xdr value, or pointer to a value, as JSON.
+// Structs become objects keyed by their field names, with a lower case
+// first letter.  Unions become objects holding their discriminant and their
+// arm, if any.  Enums are encoded by name, account ids as strkey addresses,
+// assets as "native" or "CODE:ISSUER", amounts as decimal strings (see the
+// amount package), other 64-bit integers as strings, fixed size opaque data
+// as hex and variable size opaque data as base64.  Alphanum12 assets whose
+// code would be read as an alphanum4 code are prefixed with their type, as
+// "credit_alphanum12:CODE:ISSUER".  Object keys are sorted, so equal values
+// always have the same encoding.
+func MarshalJSON(v interface{}) ([]byte, error) {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return nil, fmt.Errorf("xdr: cannot encode nil")
+	}
+
+	tree, err := toJSON(value, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(tree)
+}
+
+// UnmarshalJSON decodes JSON produced by MarshalJSON into the xdr value
+// pointed to by dest.  Decoding then encoding again as xdr is lossless.
+func UnmarshalJSON(data []byte, dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("xdr: cannot decode into non-pointer %T", dest)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var tree interface{}
+	err := decoder.Decode(&tree)
+	if err != nil {
+		return err
+	}
+
+	return fromJSON(tree, value.Elem(), "")
+}
+
+// jsonAmountFields are the names of the Int64 fields holding amounts
+var jsonAmountFields = map[string]bool{
+	"Amount":               true,
+	"AmountBought":         true,
+	"AmountSold":           true,
+	"AmountToCharge":       true,
+	"Balance":              true,
+	"CommissionAmount":     true,
+	"DestAmount":           true,
+	"FeePool":              true,
+	"FlatFee":              true,
+	"Limit":                true,
+	"PercentFee":           true,
+	"SendMax":              true,
+	"SourceAccountBalance": true,
+	"TotalCoins":           true,
+}
+
+// jsonCodeFields are the names of the fields holding asset codes, encoded
+// as strings
+var jsonCodeFields = map[string]bool{
+	"AssetCode":   true,
+	"AssetCode4":  true,
+	"AssetCode12": true,
+}
+
+// The methods below implement the json.Marshaler and json.Unmarshaler
+// interfaces with MarshalJSON and UnmarshalJSON for the xdr values commonly
+// held by other structs.  Other values can be encoded with the functions.
+
+// MarshalJSON encodes an Asset as JSON, see MarshalJSON
+func (t Asset) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes an Asset from JSON, see UnmarshalJSON
+func (t *Asset) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes a LedgerEntry as JSON, see MarshalJSON
+func (t LedgerEntry) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes a LedgerEntry from JSON, see UnmarshalJSON
+func (t *LedgerEntry) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes LedgerEntryChanges as JSON, see MarshalJSON
+func (t LedgerEntryChanges) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes LedgerEntryChanges from JSON, see UnmarshalJSON
+func (t *LedgerEntryChanges) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes a LedgerHeader as JSON, see MarshalJSON
+func (t LedgerHeader) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes a LedgerHeader from JSON, see UnmarshalJSON
+func (t *LedgerHeader) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes a LedgerKey as JSON, see MarshalJSON
+func (t LedgerKey) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes a LedgerKey from JSON, see UnmarshalJSON
+func (t *LedgerKey) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes an Operation as JSON, see MarshalJSON
+func (t Operation) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes an Operation from JSON, see UnmarshalJSON
+func (t *Operation) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes a Transaction as JSON, see MarshalJSON
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes a Transaction from JSON, see UnmarshalJSON
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes a TransactionEnvelope as JSON, see MarshalJSON
+func (t TransactionEnvelope) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes a TransactionEnvelope from JSON, see UnmarshalJSON
+func (t *TransactionEnvelope) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes a TransactionMeta as JSON, see MarshalJSON
+func (t TransactionMeta) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes a TransactionMeta from JSON, see UnmarshalJSON
+func (t *TransactionMeta) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// MarshalJSON encodes a TransactionResult as JSON, see MarshalJSON
+func (t TransactionResult) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t)
+}
+
+// UnmarshalJSON decodes a TransactionResult from JSON, see UnmarshalJSON
+func (t *TransactionResult) UnmarshalJSON(data []byte) error {
+	return UnmarshalJSON(data, t)
+}
+
+// jsonAlphanum12 prefixes the alphanum12 assets whose code is short enough to
+// be read as an alphanum4 code
+const jsonAlphanum12 = "credit_alphanum12"
+
+var (
+	jsonAccountIdType = reflect.TypeOf(AccountId{})
+	jsonPublicKeyType = reflect.TypeOf(PublicKey{})
+	jsonNodeIdType    = reflect.TypeOf(NodeId{})
+	jsonAssetType     = reflect.TypeOf(Asset{})
+)
+
+// jsonEnum is implemented by the generated enums
+type jsonEnum interface {
+	ValidEnum(int32) bool
+	String() string
+}
+
+// jsonUnion is implemented by the generated unions
+type jsonUnion interface {
+	SwitchFieldName() string
+	ArmForSwitch(int32) (string, bool)
+}
+
+// toJSON converts v, held by the named field, into a tree of values encoded
+// by encoding/json
+func toJSON(v reflect.Value, field string) (interface{}, error) {
+	t := v.Type()
+
+	switch t {
+	case jsonAccountIdType, jsonPublicKeyType, jsonNodeIdType:
+		aid := v.Convert(jsonAccountIdType).Interface().(AccountId)
+		if aid.Type != CryptoKeyTypeKeyTypeEd25519 || aid.Ed25519 == nil {
+			return nil, fmt.Errorf("xdr: cannot encode public key of type %d", aid.Type)
+		}
+		return aid.Address(), nil
+	case jsonAssetType:
+		var typ, code, issuer string
+		err := v.Interface().(Asset).Extract(&typ, &code, &issuer)
+		if err != nil {
+			return nil, err
+		}
+		if typ == "native" {
+			return typ, nil
+		}
+		if typ == jsonAlphanum12 && len(code) <= 4 {
+			return typ + ":" + code + ":" + issuer, nil
+		}
+		return code + ":" + issuer, nil
+	}
+
+	if e, ok := v.Interface().(jsonEnum); ok && v.Kind() == reflect.Int32 {
+		if !e.ValidEnum(int32(v.Int())) {
+			return nil, fmt.Errorf("xdr: invalid %s value %d", t.Name(), v.Int())
+		}
+		return e.String(), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return toJSON(v.Elem(), field)
+	case reflect.Struct:
+		if u, ok := v.Interface().(jsonUnion); ok {
+			return unionToJSON(v, u)
+		}
+
+		ret := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			name := t.Field(i).Name
+			value, err := toJSON(v.Field(i), name)
+			if err != nil {
+				return nil, err
+			}
+			ret[jsonKey(name)] = value
+		}
+		return ret, nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+		fallthrough
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			raw := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(raw), v)
+			if jsonCodeFields[field] {
+				return string(bytes.TrimRight(raw, "\x00")), nil
+			}
+			return hex.EncodeToString(raw), nil
+		}
+
+		ret := make([]interface{}, v.Len())
+		for i := range ret {
+			value, err := toJSON(v.Index(i), "")
+			if err != nil {
+				return nil, err
+			}
+			ret[i] = value
+		}
+		return ret, nil
+	case reflect.Int64:
+		if jsonAmountFields[field] {
+			return jsonAmountString(v.Int()), nil
+		}
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Int32, reflect.Uint32, reflect.Bool, reflect.String:
+		return v.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("xdr: cannot encode %s", t)
+}
+
+func unionToJSON(v reflect.Value, u jsonUnion) (interface{}, error) {
+	sw := v.FieldByName(u.SwitchFieldName())
+	discriminant, err := toJSON(sw, u.SwitchFieldName())
+	if err != nil {
+		return nil, err
+	}
+
+	ret := map[string]interface{}{
+		jsonKey(u.SwitchFieldName()): discriminant,
+	}
+
+	arm, ok := u.ArmForSwitch(jsonSwitch(sw))
+	if !ok {
+		return nil, fmt.Errorf("xdr: invalid %s switch %v", v.Type().Name(), discriminant)
+	}
+	if arm == "" {
+		return ret, nil
+	}
+
+	field := v.FieldByName(arm)
+	if field.IsNil() {
+		return nil, fmt.Errorf("xdr: %s arm %s is not set", v.Type().Name(), arm)
+	}
+
+	ret[jsonKey(arm)], err = toJSON(field.Elem(), arm)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// fromJSON sets v, held by the named field, from a tree decoded by
+// encoding/json
+func fromJSON(tree interface{}, v reflect.Value, field string) error {
+	t := v.Type()
+
+	switch t {
+	case jsonAccountIdType, jsonPublicKeyType, jsonNodeIdType:
+		address, err := jsonString(tree, t)
+		if err != nil {
+			return err
+		}
+
+		var aid AccountId
+		err = aid.SetAddress(address)
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(aid).Convert(t))
+		return nil
+	case jsonAssetType:
+		s, err := jsonString(tree, t)
+		if err != nil {
+			return err
+		}
+
+		var asset Asset
+		if s == "native" {
+			err = asset.SetNative()
+		} else {
+			i := strings.LastIndex(s, ":")
+			if i < 0 {
+				return fmt.Errorf("xdr: invalid asset %q", s)
+			}
+
+			var issuer AccountId
+			err = issuer.SetAddress(s[i+1:])
+			if err != nil {
+				return err
+			}
+
+			code := s[:i]
+			if strings.HasPrefix(code, jsonAlphanum12+":") {
+				code = strings.TrimPrefix(code, jsonAlphanum12+":")
+				if len(code) > 4 {
+					return fmt.Errorf("xdr: invalid asset %q", s)
+				}
+				body := AssetAlphaNum12{Issuer: issuer}
+				copy(body.AssetCode[:], code)
+				asset, err = NewAsset(AssetTypeAssetTypeCreditAlphanum12, body)
+			} else {
+				err = asset.SetCredit(code, issuer)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(asset))
+		return nil
+	}
+
+	if e, ok := v.Interface().(jsonEnum); ok && v.Kind() == reflect.Int32 {
+		name, err := jsonString(tree, t)
+		if err != nil {
+			return err
+		}
+
+		value, ok := jsonEnumValues(t, e)[name]
+		if !ok {
+			return fmt.Errorf("xdr: invalid %s %q", t.Name(), name)
+		}
+		v.SetInt(int64(value))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if tree == nil {
+			v.Set(reflect.Zero(t))
+			return nil
+		}
+
+		value := reflect.New(t.Elem())
+		err := fromJSON(tree, value.Elem(), field)
+		if err != nil {
+			return err
+		}
+		v.Set(value)
+		return nil
+	case reflect.Struct:
+		object, ok := tree.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("xdr: expected object for %s", t.Name())
+		}
+
+		if u, ok := v.Interface().(jsonUnion); ok {
+			return unionFromJSON(object, v, u)
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			name := t.Field(i).Name
+			value, ok := object[jsonKey(name)]
+			if !ok {
+				return fmt.Errorf("xdr: missing %s field %s", t.Name(), jsonKey(name))
+			}
+
+			err := fromJSON(value, v.Field(i), name)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s, err := jsonString(tree, t)
+			if err != nil {
+				return err
+			}
+
+			raw, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(raw).Convert(t))
+			return nil
+		}
+
+		items, ok := tree.([]interface{})
+		if !ok {
+			return fmt.Errorf("xdr: expected array for %s", t)
+		}
+
+		v.Set(reflect.MakeSlice(t, len(items), len(items)))
+		return jsonItems(items, v)
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s, err := jsonString(tree, t)
+			if err != nil {
+				return err
+			}
+
+			if jsonCodeFields[field] {
+				if len(s) > v.Len() {
+					return fmt.Errorf("xdr: asset code %q is too long", s)
+				}
+				v.Set(reflect.Zero(t))
+				reflect.Copy(v, reflect.ValueOf([]byte(s)))
+				return nil
+			}
+
+			raw, err := hex.DecodeString(s)
+			if err != nil {
+				return err
+			}
+			if len(raw) != v.Len() {
+				return fmt.Errorf("xdr: expected %d bytes for %s, got %d", v.Len(), t, len(raw))
+			}
+			reflect.Copy(v, reflect.ValueOf(raw))
+			return nil
+		}
+
+		items, ok := tree.([]interface{})
+		if !ok || len(items) != v.Len() {
+			return fmt.Errorf("xdr: expected array of %d items for %s", v.Len(), t)
+		}
+		return jsonItems(items, v)
+	case reflect.Int64:
+		s, err := jsonString(tree, t)
+		if err != nil {
+			return err
+		}
+
+		var i int64
+		if jsonAmountFields[field] {
+			i, err = jsonParseAmount(s)
+		} else {
+			i, err = strconv.ParseInt(s, 10, 64)
+		}
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+		return nil
+	case reflect.Uint64:
+		s, err := jsonString(tree, t)
+		if err != nil {
+			return err
+		}
+
+		u, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+		return nil
+	case reflect.Int32:
+		n, ok := tree.(json.Number)
+		if !ok {
+			return fmt.Errorf("xdr: expected number for %s", t)
+		}
+
+		i, err := strconv.ParseInt(string(n), 10, 32)
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+		return nil
+	case reflect.Uint32:
+		n, ok := tree.(json.Number)
+		if !ok {
+			return fmt.Errorf("xdr: expected number for %s", t)
+		}
+
+		u, err := strconv.ParseUint(string(n), 10, 32)
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+		return nil
+	case reflect.Bool:
+		b, ok := tree.(bool)
+		if !ok {
+			return fmt.Errorf("xdr: expected boolean for %s", t)
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.String:
+		s, err := jsonString(tree, t)
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+		return nil
+	}
+
+	return fmt.Errorf("xdr: cannot decode %s", t)
+}
+
+func unionFromJSON(object map[string]interface{}, v reflect.Value, u jsonUnion) error {
+	v.Set(reflect.Zero(v.Type()))
+
+	name := u.SwitchFieldName()
+	sw := v.FieldByName(name)
+	err := fromJSON(object[jsonKey(name)], sw, name)
+	if err != nil {
+		return err
+	}
+
+	arm, ok := u.ArmForSwitch(jsonSwitch(sw))
+	if !ok {
+		return fmt.Errorf("xdr: invalid %s switch %v", v.Type().Name(), object[jsonKey(name)])
+	}
+	if arm == "" {
+		return nil
+	}
+
+	field := v.FieldByName(arm)
+	value := reflect.New(field.Type().Elem())
+	err = fromJSON(object[jsonKey(arm)], value.Elem(), arm)
+	if err != nil {
+		return err
+	}
+	field.Set(value)
+	return nil
+}
+
+func jsonItems(items []interface{}, v reflect.Value) error {
+	for i, item := range items {
+		err := fromJSON(item, v.Index(i), "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func jsonString(tree interface{}, t reflect.Type) (string, error) {
+	s, ok := tree.(string)
+	if !ok {
+		return "", fmt.Errorf("xdr: expected string for %s", t)
+	}
+	return s, nil
+}
+
+// jsonKey returns the JSON key of a field: its name with a lower case first
+// letter
+func jsonKey(field string) string {
+	return strings.ToLower(field[:1]) + field[1:]
+}
+
+// jsonSwitch returns the value of a union discriminant
+func jsonSwitch(sw reflect.Value) int32 {
+	switch sw.Kind() {
+	case reflect.Bool:
+		if sw.Bool() {
+			return 1
+		}
+		return 0
+	case reflect.Uint32:
+		return int32(sw.Uint())
+	default:
+		return int32(sw.Int())
+	}
+}
+
+// jsonEnumRange bounds the values searched for enum names.  The values of
+// every enum in the protocol lie within it.
+const jsonEnumRange = 1024
+
+var jsonEnumCache sync.Map
+
+// jsonEnumValues returns the values of the enum of type t, by name
+func jsonEnumValues(t reflect.Type, e jsonEnum) map[string]int32 {
+	if cached, ok := jsonEnumCache.Load(t); ok {
+		return cached.(map[string]int32)
+	}
+
+	values := map[string]int32{}
+	value := reflect.New(t).Elem()
+	for i := int32(-jsonEnumRange); i <= jsonEnumRange; i++ {
+		if !e.ValidEnum(i) {
+			continue
+		}
+		value.SetInt(int64(i))
+		values[value.Interface().(jsonEnum).String()] = i
+	}
+
+	jsonEnumCache.Store(t, values)
+	return values
+}
+
+// jsonAmountOne is the raw value of one unit of an asset.  The amount
+// package, which imports xdr, cannot be used here; jsonAmountString and
+// jsonParseAmount match amount.String and amount.Parse.
+const jsonAmountOne = 10000000
+
+func jsonAmountString(v int64) string {
+	r := new(big.Rat).SetFrac(big.NewInt(v), big.NewInt(jsonAmountOne))
+	return r.FloatString(7)
+}
+
+// jsonParseAmount parses an amount, rejecting amounts more precise than the
+// 7 digits of raw values
+func jsonParseAmount(s string) (int64, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("xdr: invalid amount %q", s)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt64(jsonAmountOne))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, fmt.Errorf("xdr: invalid amount %q", s)
+	}
+	return r.Num().Int64(), nil
+}
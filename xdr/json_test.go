@@ -0,0 +1,278 @@
+package xdr_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"bitbucket.org/atticlab/go-smart-base/amount"
+	"bitbucket.org/atticlab/go-smart-base/build"
+	. "bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+var _ = Describe("xdr JSON encoding", func() {
+	const (
+		seed   = "SDOTALIMPAM2IV65IOZA7KZL7XWZI5BODFXTRVLIHLQZQCKK57PH5F3H"
+		source = "GA3FR7TVTDJAY6TN4MUX7BF4KK6SUHWIYDY7NRNUDTA4OVY3IMY7B6H5"
+		issuer = "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+		dest   = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+	)
+
+	// roundTrip checks that the value encoded as base64 in b64 is the same
+	// after a JSON round trip, and returns its JSON encoding
+	roundTrip := func(b64 string, dest interface{}, decoded interface{}) []byte {
+		Expect(SafeUnmarshalBase64(b64, dest)).To(Succeed())
+
+		encoded, err := MarshalJSON(dest)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(UnmarshalJSON(encoded, decoded)).To(Succeed())
+		Expect(MarshalBase64(decoded)).To(Equal(b64))
+
+		again, err := MarshalJSON(decoded)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(encoded))
+		return encoded
+	}
+
+	Describe("envelopes", func() {
+		var b64 string
+
+		BeforeEach(func() {
+			usd := build.CreditAsset("USD", issuer)
+			tx := build.Transaction(
+				build.SourceAccount{AddressOrSeed: seed},
+				build.Sequence{Sequence: 2},
+				build.TestNetwork,
+				build.MemoText{Value: "audit"},
+				build.CreateAccount(build.Destination{AddressOrSeed: dest}),
+				build.Payment(
+					build.Destination{AddressOrSeed: dest},
+					build.CreditAmount{Code: "USD", Issuer: issuer, Amount: "10.5"},
+				),
+				build.Payment(
+					build.Destination{AddressOrSeed: dest},
+					build.CreditAmount{Code: "EURO1", Issuer: issuer, Amount: "1"},
+					build.PayWith(usd, "2").Through(build.NativeAsset()),
+				),
+				build.CreateOffer(build.Rate{Selling: usd, Buying: build.NativeAsset(), Price: "0.25"}, "100"),
+				build.Trust("USD", issuer),
+				build.SetOptions(build.HomeDomain("example.com"), build.SetThresholds(1, 2, 3)),
+			)
+			envelope := tx.Sign(seed)
+
+			var err error
+			b64, err = envelope.Base64()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("round trips", func() {
+			var e, decoded TransactionEnvelope
+			roundTrip(b64, &e, &decoded)
+		})
+
+		It("implements json.Marshaler and json.Unmarshaler", func() {
+			type record struct {
+				Envelope TransactionEnvelope `json:"envelope"`
+				Result   *TransactionResult  `json:"result"`
+			}
+
+			var original record
+			Expect(SafeUnmarshalBase64(b64, &original.Envelope)).To(Succeed())
+
+			encoded, err := json.Marshal(original)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(encoded)).To(ContainSubstring(`"sourceAccount":"` + source + `"`))
+			Expect(string(encoded)).To(ContainSubstring(`"result":null`))
+
+			var decoded record
+			Expect(json.Unmarshal(encoded, &decoded)).To(Succeed())
+			Expect(MarshalBase64(decoded.Envelope)).To(Equal(b64))
+			Expect(decoded.Result).To(BeNil())
+		})
+
+		It("uses readable values", func() {
+			var e, decoded TransactionEnvelope
+			encoded := roundTrip(b64, &e, &decoded)
+
+			var tree struct {
+				Tx struct {
+					SourceAccount string `json:"sourceAccount"`
+					SeqNum        string `json:"seqNum"`
+					Memo          struct {
+						Type string `json:"type"`
+						Text string `json:"text"`
+					} `json:"memo"`
+					Operations []struct {
+						Body struct {
+							Type      string `json:"type"`
+							PaymentOp struct {
+								Destination string `json:"destination"`
+								Asset       string `json:"asset"`
+								Amount      string `json:"amount"`
+							} `json:"paymentOp"`
+						} `json:"body"`
+					} `json:"operations"`
+				} `json:"tx"`
+			}
+			Expect(json.Unmarshal(encoded, &tree)).To(Succeed())
+
+			Expect(tree.Tx.SourceAccount).To(Equal(source))
+			Expect(tree.Tx.SeqNum).To(Equal("2"))
+			Expect(tree.Tx.Memo.Type).To(Equal("MemoTypeMemoText"))
+			Expect(tree.Tx.Memo.Text).To(Equal("audit"))
+
+			payment := tree.Tx.Operations[1].Body
+			Expect(payment.Type).To(Equal("OperationTypePayment"))
+			Expect(payment.PaymentOp.Destination).To(Equal(dest))
+			Expect(payment.PaymentOp.Asset).To(Equal("USD:" + issuer))
+			Expect(payment.PaymentOp.Amount).To(Equal(amount.String(105000000)))
+		})
+	})
+
+	Describe("assets", func() {
+		var issuerID AccountId
+
+		BeforeEach(func() { Expect(issuerID.SetAddress(issuer)).To(Succeed()) })
+
+		alphanum12 := func(code string) Asset {
+			body := AssetAlphaNum12{Issuer: issuerID}
+			copy(body.AssetCode[:], code)
+			asset, err := NewAsset(AssetTypeAssetTypeCreditAlphanum12, body)
+			Expect(err).NotTo(HaveOccurred())
+			return asset
+		}
+
+		It("keeps the type of alphanum12 assets with short codes", func() {
+			var destination AccountId
+			Expect(destination.SetAddress(dest)).To(Succeed())
+			body, err := NewOperationBody(OperationTypePayment, PaymentOp{
+				Destination: destination,
+				Asset:       alphanum12("USD"),
+				Amount:      10,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			b64, err := MarshalBase64(TransactionEnvelope{Tx: Transaction{
+				SourceAccount: issuerID,
+				SeqNum:        1,
+				Operations:    []Operation{{Body: body}},
+			}})
+			Expect(err).NotTo(HaveOccurred())
+
+			var e, decoded TransactionEnvelope
+			encoded := roundTrip(b64, &e, &decoded)
+			Expect(string(encoded)).To(ContainSubstring(`"asset":"credit_alphanum12:USD:` + issuer + `"`))
+			Expect(decoded.Tx.Operations[0].Body.MustPaymentOp().Asset.Type).To(Equal(AssetTypeAssetTypeCreditAlphanum12))
+		})
+
+		It("encodes other credit assets by code and issuer", func() {
+			var usd Asset
+			Expect(usd.SetCredit("USD", issuerID)).To(Succeed())
+
+			for _, asset := range []Asset{usd, alphanum12("EURO1")} {
+				b64, err := MarshalBase64(asset)
+				Expect(err).NotTo(HaveOccurred())
+
+				var decoded Asset
+				encoded := roundTrip(b64, &asset, &decoded)
+				Expect(string(encoded)).NotTo(ContainSubstring("credit_"))
+			}
+		})
+
+		It("rejects prefixed codes that are not ambiguous", func() {
+			var asset Asset
+			Expect(UnmarshalJSON([]byte(`"credit_alphanum12:EURO1:`+issuer+`"`), &asset)).NotTo(Succeed())
+		})
+	})
+
+	Describe("results", func() {
+		It("round trips failed transactions", func() {
+			var result, decoded TransactionResult
+			encoded := roundTrip("/////wAAAAEAAAAAAAAADP///+0AAAAA", &result, &decoded)
+			Expect(string(encoded)).To(ContainSubstring(`"code":"TransactionResultCodeTxFailed"`))
+			Expect(string(encoded)).To(ContainSubstring(`"code":"PaymentReversalResultCodePaymentReversalAlreadyReversed"`))
+		})
+
+		It("round trips transaction level failures", func() {
+			result := TransactionResult{Result: TransactionResultResult{Code: TransactionResultCodeTxBadSeq}}
+			b64, err := MarshalBase64(result)
+			Expect(err).NotTo(HaveOccurred())
+
+			var decoded TransactionResult
+			roundTrip(b64, &result, &decoded)
+		})
+	})
+
+	Describe("meta", func() {
+		It("round trips ledger entry changes", func() {
+			var account, trustor AccountId
+			Expect(account.SetAddress(source)).To(Succeed())
+			Expect(trustor.SetAddress(dest)).To(Succeed())
+
+			var usd Asset
+			Expect(usd.SetCredit("USD", account)).To(Succeed())
+
+			created := LedgerEntry{
+				LastModifiedLedgerSeq: 3,
+				Data: LedgerEntryData{
+					Type: LedgerEntryTypeAccount,
+					Account: &AccountEntry{
+						AccountId:     account,
+						Balance:       -1,
+						SeqNum:        12884901888,
+						InflationDest: &trustor,
+						HomeDomain:    "example.com",
+						Thresholds:    Thresholds{1, 0, 0, 0},
+						Signers:       []Signer{{PubKey: trustor, Weight: 1}},
+					},
+				},
+			}
+			updated := LedgerEntry{
+				LastModifiedLedgerSeq: 4,
+				Data: LedgerEntryData{
+					Type: LedgerEntryTypeTrustline,
+					TrustLine: &TrustLineEntry{
+						AccountId: trustor,
+						Asset:     usd,
+						Balance:   1234567,
+						Limit:     9223372036854775807,
+					},
+				},
+			}
+
+			operations := []OperationMeta{{Changes: LedgerEntryChanges{
+				{Type: LedgerEntryChangeTypeLedgerEntryCreated, Created: &created},
+				{Type: LedgerEntryChangeTypeLedgerEntryState, State: &updated},
+				{Type: LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &updated},
+				{Type: LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &LedgerKey{
+					Type:      LedgerEntryTypeTrustline,
+					TrustLine: &LedgerKeyTrustLine{AccountId: trustor, Asset: usd},
+				}},
+			}}}
+			b64, err := MarshalBase64(TransactionMeta{Operations: &operations})
+			Expect(err).NotTo(HaveOccurred())
+
+			var meta, decoded TransactionMeta
+			encoded := roundTrip(b64, &meta, &decoded)
+			Expect(string(encoded)).To(ContainSubstring(`"balance":"0.1234567"`))
+			Expect(string(encoded)).To(ContainSubstring(`"balance":"-0.0000001"`))
+			Expect(string(encoded)).To(ContainSubstring(`"limit":"922337203685.4775807"`))
+		})
+	})
+
+	Describe("errors", func() {
+		It("rejects invalid input", func() {
+			var asset Asset
+			Expect(UnmarshalJSON([]byte(`"USD"`), &asset)).NotTo(Succeed())
+			Expect(UnmarshalJSON([]byte(`"native"`), asset)).NotTo(Succeed())
+
+			var result TransactionResult
+			Expect(UnmarshalJSON([]byte(`{"result":{"code":"NotACode"},"ext":{"v":0}}`), &result)).NotTo(Succeed())
+
+			var amountHolder PaymentOp
+			Expect(UnmarshalJSON([]byte(`{"destination":"`+dest+`","asset":"native","amount":"0.00000001"}`), &amountHolder)).NotTo(Succeed())
+		})
+	})
+})
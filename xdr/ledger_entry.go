@@ -30,6 +30,11 @@ func (entry *LedgerEntry) LedgerKey() LedgerKey {
 			AccountId: tline.AccountId,
 			Asset:     tline.Asset,
 		}
+	case LedgerEntryTypeReversedPayment:
+		reversed := entry.Data.MustReversedPayment()
+		body = LedgerKeyReversedPayment{
+			Id: reversed.Id,
+		}
 	default:
 		panic(fmt.Errorf("Unknown entry type: %v", entry.Data.Type))
 	}
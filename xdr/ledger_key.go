@@ -30,6 +30,10 @@ func (key *LedgerKey) Equals(other LedgerKey) bool {
 		l := key.MustTrustLine()
 		r := other.MustTrustLine()
 		return l.AccountId.Equals(r.AccountId) && l.Asset.Equals(r.Asset)
+	case LedgerEntryTypeReversedPayment:
+		l := key.MustReversedPayment()
+		r := other.MustReversedPayment()
+		return l.Id == r.Id
 	default:
 		panic(fmt.Errorf("Unknown ledger key type: %v", key.Type))
 	}
@@ -73,6 +77,19 @@ func (key *LedgerKey) SetOffer(account AccountId, id uint64) error {
 	return nil
 }
 
+// SetReversedPayment mutates `key` such that it represents the identity of
+// the entry recording the reversal of payment `id`.
+func (key *LedgerKey) SetReversedPayment(id int64) error {
+	data := LedgerKeyReversedPayment{Int64(id)}
+	nkey, err := NewLedgerKey(LedgerEntryTypeReversedPayment, data)
+	if err != nil {
+		return err
+	}
+
+	*key = nkey
+	return nil
+}
+
 // SetTrustline mutates `key` such that it represents the identity of the
 // trustline owned by `account` and for `asset`.
 func (key *LedgerKey) SetTrustline(account AccountId, line Asset) error {
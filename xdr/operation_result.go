@@ -0,0 +1,51 @@
+package xdr
+
+import "fmt"
+
+// ResultCode returns the result code specific to the type of the operation,
+// such as a PaymentResultCode for payments, so that callers may compare it to
+// the generated constants.  When the operation was not applied or its type
+// has no specific result, r.Code is returned.
+func (r *OperationResult) ResultCode() fmt.Stringer {
+	if r.Code != OperationResultCodeOpInner || r.Tr == nil {
+		return r.Code
+	}
+
+	tr := r.Tr
+	switch tr.Type {
+	case OperationTypeCreateAccount:
+		return tr.MustCreateAccountResult().Code
+	case OperationTypePayment, OperationTypeExternalPayment:
+		return tr.MustPaymentResult().Code
+	case OperationTypePathPayment:
+		return tr.MustPathPaymentResult().Code
+	case OperationTypeManageOffer:
+		return tr.MustManageOfferResult().Code
+	case OperationTypeCreatePassiveOffer:
+		return tr.MustCreatePassiveOfferResult().Code
+	case OperationTypeSetOptions:
+		return tr.MustSetOptionsResult().Code
+	case OperationTypeChangeTrust:
+		return tr.MustChangeTrustResult().Code
+	case OperationTypeAllowTrust:
+		return tr.MustAllowTrustResult().Code
+	case OperationTypeAccountMerge:
+		return tr.MustAccountMergeResult().Code
+	case OperationTypeInflation:
+		return tr.MustInflationResult().Code
+	case OperationTypeManageData:
+		return tr.MustManageDataResult().Code
+	case OperationTypeAdministrative:
+		return tr.MustAdminResult().Code
+	case OperationTypePaymentReversal:
+		return tr.MustPaymentReversalResult().Code
+	}
+
+	return r.Code
+}
+
+// OperationResults returns the results of the transaction's operations, which
+// are only available when the transaction was applied, successfully or not.
+func (r *TransactionResult) OperationResults() ([]OperationResult, bool) {
+	return r.Result.GetResults()
+}
@@ -0,0 +1,26 @@
+package xdr_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+var _ = Describe("xdr.OperationResult#ResultCode()", func() {
+	It("returns the operation specific code", func() {
+		var result TransactionResult
+		err := SafeUnmarshalBase64("/////wAAAAEAAAAAAAAADP///+0AAAAA", &result)
+		Expect(err).NotTo(HaveOccurred())
+
+		results, ok := result.OperationResults()
+		Expect(ok).To(BeTrue())
+		Expect(results).To(HaveLen(1))
+		Expect(results[0].ResultCode()).To(Equal(PaymentReversalResultCodePaymentReversalAlreadyReversed))
+	})
+
+	It("returns the operation code when the operation was not applied", func() {
+		result := OperationResult{Code: OperationResultCodeOpNoAccount}
+		Expect(result.ResultCode()).To(Equal(OperationResultCodeOpNoAccount))
+	})
+})
@@ -0,0 +1,123 @@
+package xdr
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxRecordSize is the default bound on the size of the records read
+// by a Stream.  It is far above the size of the largest history entries.
+const DefaultMaxRecordSize = 64 << 20
+
+// recordLastFragment is the flag set in the header of the last fragment of
+// a record
+const recordLastFragment = 0x80000000
+
+// Stream reads xdr values from a stream of records, marked as described in
+// RFC 5531 section 11, such as the files of history archives.  Streams
+// compressed with gzip are decompressed.  A single record is held in memory
+// at a time.
+type Stream struct {
+	// MaxRecordSize bounds the size of the records read, so that corrupted
+	// or malicious streams cannot exhaust memory
+	MaxRecordSize int
+
+	r    io.Reader
+	gzip *gzip.Reader
+	buf  bytes.Buffer
+}
+
+// NewStream returns a stream reading records from r, decompressing it when it
+// starts with the gzip magic number.
+func NewStream(r io.Reader) (*Stream, error) {
+	br := bufio.NewReader(r)
+	s := &Stream{MaxRecordSize: DefaultMaxRecordSize, r: br}
+
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		s.gzip, err = gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		s.r = s.gzip
+	}
+
+	return s, nil
+}
+
+// ReadOne decodes the next record of the stream into dest, which must be
+// fully consumed by it.  io.EOF is returned once all records have been read.
+func (s *Stream) ReadOne(dest interface{}) error {
+	s.buf.Reset()
+
+	var header [4]byte
+	for first := true; ; first = false {
+		_, err := io.ReadFull(s.r, header[:])
+		if err == io.EOF && first {
+			return io.EOF
+		}
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		marker := binary.BigEndian.Uint32(header[:])
+		size := int64(marker &^ recordLastFragment)
+		if int64(s.buf.Len())+size > int64(s.MaxRecordSize) {
+			return fmt.Errorf("xdr: record larger than %d bytes", s.MaxRecordSize)
+		}
+
+		_, err = io.CopyN(&s.buf, s.r, size)
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+
+		if marker&recordLastFragment != 0 {
+			break
+		}
+	}
+
+	return SafeUnmarshal(s.buf.Bytes(), dest)
+}
+
+// Close releases the resources of the stream.  The underlying reader is not
+// closed.
+func (s *Stream) Close() error {
+	if s.gzip != nil {
+		return s.gzip.Close()
+	}
+	return nil
+}
+
+// MarshalFramed writes v to w as a single record marked as described in
+// RFC 5531, the format read by Stream.
+func MarshalFramed(w io.Writer, v interface{}) (int, error) {
+	var raw bytes.Buffer
+	_, err := Marshal(&raw, v)
+	if err != nil {
+		return 0, err
+	}
+
+	if raw.Len() > int(^uint32(recordLastFragment)) {
+		return 0, fmt.Errorf("xdr: record of %d bytes is too large", raw.Len())
+	}
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(raw.Len())|recordLastFragment)
+	n, err := w.Write(header[:])
+	if err != nil {
+		return n, err
+	}
+
+	m, err := w.Write(raw.Bytes())
+	return n + m, err
+}
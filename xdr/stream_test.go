@@ -0,0 +1,129 @@
+package xdr_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+var _ = Describe("xdr.Stream", func() {
+	var raw bytes.Buffer
+
+	entry := func(seq uint32) LedgerHeaderHistoryEntry {
+		return LedgerHeaderHistoryEntry{
+			Hash: Hash{byte(seq)},
+			Header: LedgerHeader{
+				LedgerVersion: 1,
+				LedgerSeq:     Uint32(seq),
+				MaxTxSetSize:  50,
+			},
+		}
+	}
+
+	// encoded returns the base64 encodings of entries, so that decoded entries
+	// are compared by value regardless of how empty arrays are decoded
+	encoded := func(entries ...LedgerHeaderHistoryEntry) []string {
+		ret := make([]string, len(entries))
+		for i, e := range entries {
+			var err error
+			ret[i], err = MarshalBase64(e)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return ret
+	}
+
+	readAll := func(r io.Reader) []LedgerHeaderHistoryEntry {
+		stream, err := NewStream(r)
+		Expect(err).NotTo(HaveOccurred())
+		defer stream.Close()
+
+		var entries []LedgerHeaderHistoryEntry
+		for {
+			var e LedgerHeaderHistoryEntry
+			err := stream.ReadOne(&e)
+			if err == io.EOF {
+				return entries
+			}
+			Expect(err).NotTo(HaveOccurred())
+			entries = append(entries, e)
+		}
+	}
+
+	BeforeEach(func() {
+		raw.Reset()
+		for seq := uint32(1); seq <= 3; seq++ {
+			_, err := MarshalFramed(&raw, entry(seq))
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("reads records one at a time", func() {
+		entries := readAll(bytes.NewReader(raw.Bytes()))
+		Expect(encoded(entries...)).To(Equal(encoded(entry(1), entry(2), entry(3))))
+		Expect(entries[1].Header.LedgerSeq).To(BeEquivalentTo(2))
+	})
+
+	It("reads gzip compressed streams", func() {
+		var compressed bytes.Buffer
+		w := gzip.NewWriter(&compressed)
+		_, err := w.Write(raw.Bytes())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		entries := readAll(&compressed)
+		Expect(entries).To(HaveLen(3))
+		Expect(encoded(entries[2])).To(Equal(encoded(entry(3))))
+	})
+
+	It("reads empty streams", func() {
+		Expect(readAll(&bytes.Buffer{})).To(BeEmpty())
+	})
+
+	It("joins records split into fragments", func() {
+		var body bytes.Buffer
+		_, err := Marshal(&body, entry(7))
+		Expect(err).NotTo(HaveOccurred())
+		data := body.Bytes()
+
+		var fragmented bytes.Buffer
+		fragmented.Write([]byte{0x00, 0x00, 0x00, 0x08})
+		fragmented.Write(data[:8])
+		size := len(data) - 8
+		fragmented.Write([]byte{0x80, 0x00, byte(size >> 8), byte(size)})
+		fragmented.Write(data[8:])
+
+		Expect(encoded(readAll(&fragmented)...)).To(Equal(encoded(entry(7))))
+	})
+
+	It("rejects truncated streams", func() {
+		stream, err := NewStream(bytes.NewReader(raw.Bytes()[:raw.Len()-1]))
+		Expect(err).NotTo(HaveOccurred())
+
+		var e LedgerHeaderHistoryEntry
+		Expect(stream.ReadOne(&e)).To(Succeed())
+		Expect(stream.ReadOne(&e)).To(Succeed())
+		Expect(stream.ReadOne(&e)).To(Equal(io.ErrUnexpectedEOF))
+	})
+
+	It("rejects records larger than the maximum size", func() {
+		stream, err := NewStream(bytes.NewReader(raw.Bytes()))
+		Expect(err).NotTo(HaveOccurred())
+		stream.MaxRecordSize = 16
+
+		var e LedgerHeaderHistoryEntry
+		Expect(stream.ReadOne(&e)).To(MatchError("xdr: record larger than 16 bytes"))
+	})
+
+	It("rejects records not fully consumed", func() {
+		stream, err := NewStream(bytes.NewReader(raw.Bytes()))
+		Expect(err).NotTo(HaveOccurred())
+
+		var seq Uint32
+		Expect(stream.ReadOne(&seq)).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,36 @@
+package xdr
+
+import (
+	"time"
+)
+
+// MinTimeAt returns the earliest time at which a transaction with these time
+// bounds may be applied.
+func (tb *TimeBounds) MinTimeAt() time.Time {
+	return time.Unix(int64(tb.MinTime), 0).UTC()
+}
+
+// MaxTimeAt returns the time after which a transaction with these time bounds
+// expires.  ok is false when the transaction never expires.
+func (tb *TimeBounds) MaxTimeAt() (t time.Time, ok bool) {
+	if tb.MaxTime == 0 {
+		return
+	}
+
+	return time.Unix(int64(tb.MaxTime), 0).UTC(), true
+}
+
+// ValidAt returns true if a transaction with these time bounds may be applied
+// in a ledger closing at `t`.  Both bounds are inclusive.
+func (tb *TimeBounds) ValidAt(t time.Time) bool {
+	if tb == nil {
+		return true
+	}
+
+	unix := t.Unix()
+	if unix < 0 || uint64(unix) < uint64(tb.MinTime) {
+		return false
+	}
+
+	return tb.MaxTime == 0 || uint64(unix) <= uint64(tb.MaxTime)
+}
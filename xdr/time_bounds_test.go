@@ -0,0 +1,60 @@
+package xdr_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+var _ = Describe("xdr.TimeBounds", func() {
+	var subject *TimeBounds
+
+	BeforeEach(func() {
+		subject = &TimeBounds{MinTime: 1000, MaxTime: 2000}
+	})
+
+	Describe("MinTimeAt", func() {
+		It("returns the lower bound", func() {
+			Expect(subject.MinTimeAt()).To(Equal(time.Unix(1000, 0).UTC()))
+		})
+	})
+
+	Describe("MaxTimeAt", func() {
+		It("returns the upper bound", func() {
+			max, ok := subject.MaxTimeAt()
+			Expect(ok).To(BeTrue())
+			Expect(max).To(Equal(time.Unix(2000, 0).UTC()))
+		})
+
+		It("reports a missing upper bound", func() {
+			subject.MaxTime = 0
+			_, ok := subject.MaxTimeAt()
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("ValidAt", func() {
+		It("includes both bounds", func() {
+			Expect(subject.ValidAt(time.Unix(1000, 0))).To(BeTrue())
+			Expect(subject.ValidAt(time.Unix(2000, 0))).To(BeTrue())
+		})
+
+		It("excludes times outside of the bounds", func() {
+			Expect(subject.ValidAt(time.Unix(999, 0))).To(BeFalse())
+			Expect(subject.ValidAt(time.Unix(2001, 0))).To(BeFalse())
+		})
+
+		It("has no upper bound when MaxTime is zero", func() {
+			subject.MaxTime = 0
+			Expect(subject.ValidAt(time.Unix(1<<40, 0))).To(BeTrue())
+		})
+
+		It("is always valid without time bounds", func() {
+			subject = nil
+			Expect(subject.ValidAt(time.Unix(0, 0))).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,338 @@
+package xdr
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MarshalTxRep encodes the provided xdr value, or pointer to a value, as
+// lines of "key: value" text, in the style of stellar's txrep.  It is meant
+// for transaction envelopes, so they can be reviewed, diffed and edited by
+// hand before being signed.
+//
+// Keys are the paths of the leaves of the JSON encoding of the value (see
+// MarshalJSON), in field order: struct fields and union arms are separated by
+// dots and array items are indexed in brackets.  Arrays have a "len" key and
+// optional values a "_present" key.  Values are written as encoded in JSON,
+// strings only being quoted when they could not be read back otherwise.
+func MarshalTxRep(v interface{}) (string, error) {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return "", fmt.Errorf("xdr: cannot encode nil")
+	}
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return "", fmt.Errorf("xdr: cannot encode nil")
+		}
+		value = value.Elem()
+	}
+
+	var lines []string
+	err := toTxRep(value, "", "", &lines)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// UnmarshalTxRep decodes text produced by MarshalTxRep into the xdr value
+// pointed to by dest.  Blank lines and lines starting with # are ignored.
+// Every key of the value must be present and no other key is allowed.
+func UnmarshalTxRep(text string, dest interface{}) error {
+	value := reflect.ValueOf(dest)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("xdr: cannot decode into non-pointer %T", dest)
+	}
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.Index(line, ":")
+		if i < 0 {
+			return fmt.Errorf("xdr: line %d: expected key: value", n)
+		}
+
+		key := strings.TrimSpace(line[:i])
+		if _, ok := values[key]; ok {
+			return fmt.Errorf("xdr: line %d: duplicate key %s", n, key)
+		}
+		values[key] = strings.TrimSpace(line[i+1:])
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	r := txRepReader{values: values, used: map[string]bool{}}
+	tree, err := r.read(value.Elem().Type(), "", "")
+	if err != nil {
+		return err
+	}
+
+	for key := range values {
+		if !r.used[key] {
+			return fmt.Errorf("xdr: unknown key %s", key)
+		}
+	}
+
+	return fromJSON(tree, value.Elem(), "")
+}
+
+// toTxRep appends the lines encoding v, held by the named field, at key
+func toTxRep(v reflect.Value, key, field string, lines *[]string) error {
+	t := v.Type()
+
+	if txRepLeaf(t) {
+		leaf, err := toJSON(v, field)
+		if err != nil {
+			return err
+		}
+
+		var value string
+		switch leaf := leaf.(type) {
+		case string:
+			value = txRepQuote(leaf)
+		default:
+			value = fmt.Sprint(leaf)
+		}
+		*lines = append(*lines, key+": "+value)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			*lines = append(*lines, txRepKey(key, "_present")+": false")
+			return nil
+		}
+		*lines = append(*lines, txRepKey(key, "_present")+": true")
+		return toTxRep(v.Elem(), key, field, lines)
+	case reflect.Struct:
+		if u, ok := v.Interface().(jsonUnion); ok {
+			name := u.SwitchFieldName()
+			sw := v.FieldByName(name)
+			err := toTxRep(sw, txRepKey(key, jsonKey(name)), name, lines)
+			if err != nil {
+				return err
+			}
+
+			arm, ok := u.ArmForSwitch(jsonSwitch(sw))
+			if !ok {
+				return fmt.Errorf("xdr: invalid %s switch %d", t.Name(), jsonSwitch(sw))
+			}
+			if arm == "" {
+				return nil
+			}
+
+			value := v.FieldByName(arm)
+			if value.IsNil() {
+				return fmt.Errorf("xdr: %s arm %s is not set", t.Name(), arm)
+			}
+			return toTxRep(value.Elem(), txRepKey(key, jsonKey(arm)), arm, lines)
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			name := t.Field(i).Name
+			err := toTxRep(v.Field(i), txRepKey(key, jsonKey(name)), name, lines)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice {
+			*lines = append(*lines, fmt.Sprintf("%s: %d", txRepKey(key, "len"), v.Len()))
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			err := toTxRep(v.Index(i), fmt.Sprintf("%s[%d]", key, i), "", lines)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("xdr: cannot encode %s", t)
+}
+
+// txRepReader builds, from the values of txrep lines, the tree decoded by
+// fromJSON
+type txRepReader struct {
+	values map[string]string
+	used   map[string]bool
+}
+
+// value returns the value at key, marking it as used
+func (r *txRepReader) value(key string) (string, error) {
+	value, ok := r.values[key]
+	if !ok {
+		return "", fmt.Errorf("xdr: missing key %s", key)
+	}
+	r.used[key] = true
+	return value, nil
+}
+
+// read returns the tree of the value of type t, held by the named field, at
+// key
+func (r *txRepReader) read(t reflect.Type, key, field string) (interface{}, error) {
+	if txRepLeaf(t) {
+		value, err := r.value(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, enum := reflect.Zero(t).Interface().(jsonEnum); !enum {
+			switch t.Kind() {
+			case reflect.Int32, reflect.Uint32:
+				return json.Number(value), nil
+			case reflect.Bool:
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return nil, fmt.Errorf("xdr: invalid boolean %s: %s", key, value)
+				}
+				return b, nil
+			}
+		}
+
+		if strings.HasPrefix(value, `"`) {
+			s, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("xdr: invalid string %s: %s", key, value)
+			}
+			return s, nil
+		}
+		return value, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		present, err := r.value(txRepKey(key, "_present"))
+		if err != nil {
+			return nil, err
+		}
+
+		switch present {
+		case "true":
+			return r.read(t.Elem(), key, field)
+		case "false":
+			return nil, nil
+		}
+		return nil, fmt.Errorf("xdr: invalid boolean %s: %s", txRepKey(key, "_present"), present)
+	case reflect.Struct:
+		ret := map[string]interface{}{}
+
+		if u, ok := reflect.Zero(t).Interface().(jsonUnion); ok {
+			name := u.SwitchFieldName()
+			sf, _ := t.FieldByName(name)
+			discriminant, err := r.read(sf.Type, txRepKey(key, jsonKey(name)), name)
+			if err != nil {
+				return nil, err
+			}
+			ret[jsonKey(name)] = discriminant
+
+			sw := reflect.New(sf.Type).Elem()
+			err = fromJSON(discriminant, sw, name)
+			if err != nil {
+				return nil, err
+			}
+
+			arm, ok := u.ArmForSwitch(jsonSwitch(sw))
+			if !ok {
+				return nil, fmt.Errorf("xdr: invalid %s switch %v", t.Name(), discriminant)
+			}
+			if arm == "" {
+				return ret, nil
+			}
+
+			af, _ := t.FieldByName(arm)
+			ret[jsonKey(arm)], err = r.read(af.Type.Elem(), txRepKey(key, jsonKey(arm)), arm)
+			if err != nil {
+				return nil, err
+			}
+			return ret, nil
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			name := t.Field(i).Name
+			value, err := r.read(t.Field(i).Type, txRepKey(key, jsonKey(name)), name)
+			if err != nil {
+				return nil, err
+			}
+			ret[jsonKey(name)] = value
+		}
+		return ret, nil
+	case reflect.Slice, reflect.Array:
+		n := 0
+		if t.Kind() == reflect.Array {
+			n = t.Len()
+		} else {
+			value, err := r.value(txRepKey(key, "len"))
+			if err != nil {
+				return nil, err
+			}
+
+			// every item is written on at least one line, so lengths above the
+			// number of lines not read yet are rejected before allocating
+			n, err = strconv.Atoi(value)
+			if err != nil || n < 0 || n > len(r.values)-len(r.used) {
+				return nil, fmt.Errorf("xdr: invalid length %s: %s", txRepKey(key, "len"), value)
+			}
+		}
+
+		ret := make([]interface{}, n)
+		for i := range ret {
+			var err error
+			ret[i], err = r.read(t.Elem(), fmt.Sprintf("%s[%d]", key, i), "")
+			if err != nil {
+				return nil, err
+			}
+		}
+		return ret, nil
+	}
+
+	return nil, fmt.Errorf("xdr: cannot decode %s", t)
+}
+
+// txRepLeaf returns true if values of type t are written on a single line
+func txRepLeaf(t reflect.Type) bool {
+	switch t {
+	case jsonAccountIdType, jsonPublicKeyType, jsonNodeIdType, jsonAssetType:
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Struct:
+		return false
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() == reflect.Uint8
+	}
+	return true
+}
+
+// txRepKey returns the key of the named child of key
+func txRepKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "." + name
+}
+
+// txRepQuote quotes s when it would not be read back as is: when it is empty,
+// starts with a quote, has surrounding spaces or needs escaping
+func txRepQuote(s string) string {
+	quoted := strconv.Quote(s)
+	if s == "" || s[0] == '"' || strings.TrimSpace(s) != s || quoted != `"`+s+`"` {
+		return quoted
+	}
+	return s
+}
@@ -0,0 +1,238 @@
+package xdr_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "bitbucket.org/atticlab/go-smart-base/xdr"
+)
+
+var _ = Describe("xdr txrep encoding", func() {
+	const (
+		source = "GA3FR7TVTDJAY6TN4MUX7BF4KK6SUHWIYDY7NRNUDTA4OVY3IMY7B6H5"
+		issuer = "GAXEMCEXBERNSRXOEKD4JAIKVECIXQCENHEBRVSPX2TTYZPMNEDSQCNQ"
+		dest   = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+	)
+
+	var (
+		envelope TransactionEnvelope
+		text     string
+	)
+
+	accountID := func(address string) AccountId {
+		var aid AccountId
+		Expect(aid.SetAddress(address)).To(Succeed())
+		return aid
+	}
+
+	body := func(typ OperationType, value interface{}) Operation {
+		b, err := NewOperationBody(typ, value)
+		Expect(err).NotTo(HaveOccurred())
+		return Operation{Body: b}
+	}
+
+	BeforeEach(func() {
+		var native, usd, euro Asset
+		Expect(native.SetNative()).To(Succeed())
+		Expect(usd.SetCredit("USD", accountID(issuer))).To(Succeed())
+		Expect(euro.SetCredit("EURO1", accountID(issuer))).To(Succeed())
+
+		trustAsset, err := NewAllowTrustOpAsset(AssetTypeAssetTypeCreditAlphanum4, [4]byte{'U', 'S', 'D'})
+		Expect(err).NotTo(HaveOccurred())
+
+		var memo Memo
+		memo, err = NewMemo(MemoTypeMemoText, "pay \"rent\"")
+		Expect(err).NotTo(HaveOccurred())
+
+		domain := String32("example.com")
+		weight := Uint32(2)
+		percent, flat := Int64(15000000), Int64(2500000)
+		value := DataValue("value")
+		opSource := accountID(issuer)
+
+		payment := body(OperationTypePayment, PaymentOp{
+			Destination: accountID(dest),
+			Asset:       usd,
+			Amount:      105000000,
+		})
+		payment.SourceAccount = &opSource
+
+		envelope = TransactionEnvelope{
+			Tx: Transaction{
+				SourceAccount: accountID(source),
+				Fee:           100,
+				SeqNum:        2,
+				TimeBounds:    &TimeBounds{MinTime: 1, MaxTime: 1500000000},
+				Memo:          memo,
+				Operations: []Operation{
+					body(OperationTypeCreateAccount, CreateAccountOp{
+						Destination: accountID(dest),
+						Body: CreateAccountOpBody{
+							AccountType: AccountTypeAccountScratchCard,
+							ScratchCard: &ScratchCard{Asset: usd, Amount: 10000000},
+						},
+					}),
+					payment,
+					body(OperationTypePathPayment, PathPaymentOp{
+						SendAsset:   usd,
+						SendMax:     20000000,
+						Destination: accountID(dest),
+						DestAsset:   euro,
+						DestAmount:  10000000,
+						Path:        []Asset{native},
+					}),
+					body(OperationTypeManageOffer, ManageOfferOp{
+						Selling: usd,
+						Buying:  native,
+						Amount:  1000000000,
+						Price:   Price{N: 1, D: 4},
+						OfferId: 7,
+					}),
+					body(OperationTypeCreatePassiveOffer, CreatePassiveOfferOp{
+						Selling: native,
+						Buying:  euro,
+						Amount:  1,
+						Price:   Price{N: 3, D: 1},
+					}),
+					body(OperationTypeSetOptions, SetOptionsOp{
+						HomeDomain: &domain,
+						Signer:     &Signer{PubKey: accountID(dest), Weight: weight, SignerType: 1},
+					}),
+					body(OperationTypeChangeTrust, ChangeTrustOp{Line: euro, Limit: 9223372036854775807}),
+					body(OperationTypeAllowTrust, AllowTrustOp{
+						Trustor:   accountID(dest),
+						Asset:     trustAsset,
+						Authorize: true,
+					}),
+					body(OperationTypeAccountMerge, accountID(dest)),
+					body(OperationTypeInflation, nil),
+					body(OperationTypeManageData, ManageDataOp{DataName: "name", DataValue: &value}),
+					body(OperationTypeAdministrative, AdministrativeOp{OpData: `{"block":true}`}),
+					body(OperationTypePaymentReversal, PaymentReversalOp{
+						PaymentSource:    accountID(dest),
+						Asset:            usd,
+						Amount:           50000000,
+						CommissionAmount: 1000000,
+						PaymentId:        42,
+					}),
+					body(OperationTypeExternalPayment, ExternalPaymentOp{
+						ExchangeAgent:      accountID(issuer),
+						DestinationBank:    accountID(issuer),
+						DestinationAccount: accountID(dest),
+						Asset:              usd,
+						Amount:             30000000,
+					}),
+				},
+			},
+			Signatures: []DecoratedSignature{{
+				Hint:      SignatureHint{1, 2, 3, 4},
+				Signature: Signature{5, 6, 7},
+			}},
+			OperationFees: []OperationFee{
+				{Type: OperationFeeTypeOpFeeNone},
+				{Type: OperationFeeTypeOpFeeCharged, Fee: &OperationFeeFee{
+					Asset:          usd,
+					AmountToCharge: 4075000,
+					PercentFee:     &percent,
+					FlatFee:        &flat,
+				}},
+			},
+		}
+
+		text, err = MarshalTxRep(&envelope)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("round trips every operation type", func() {
+		Expect(envelope.Tx.Operations).To(HaveLen(14))
+
+		var decoded TransactionEnvelope
+		Expect(UnmarshalTxRep(text, &decoded)).To(Succeed())
+
+		expected, err := MarshalBase64(envelope)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(MarshalBase64(decoded)).To(Equal(expected))
+
+		again, err := MarshalTxRep(decoded)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(text))
+	})
+
+	It("keeps the type of alphanum12 assets with short codes", func() {
+		code := AssetAlphaNum12{Issuer: accountID(issuer)}
+		copy(code.AssetCode[:], "USD")
+		short, err := NewAsset(AssetTypeAssetTypeCreditAlphanum12, code)
+		Expect(err).NotTo(HaveOccurred())
+
+		op := envelope.Tx.Operations[1].Body.MustPaymentOp()
+		op.Asset = short
+		envelope.Tx.Operations[1].Body.PaymentOp = &op
+		text, err = MarshalTxRep(&envelope)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(text).To(ContainSubstring("tx.operations[1].body.paymentOp.asset: credit_alphanum12:USD:" + issuer + "\n"))
+
+		var decoded TransactionEnvelope
+		Expect(UnmarshalTxRep(text, &decoded)).To(Succeed())
+
+		expected, err := MarshalBase64(envelope)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(MarshalBase64(decoded)).To(Equal(expected))
+	})
+
+	It("writes readable lines in field order", func() {
+		lines := strings.Split(strings.TrimSpace(text), "\n")
+		Expect(lines[:8]).To(Equal([]string{
+			"tx.sourceAccount: " + source,
+			"tx.fee: 100",
+			"tx.seqNum: 2",
+			"tx.timeBounds._present: true",
+			"tx.timeBounds.minTime: 1",
+			"tx.timeBounds.maxTime: 1500000000",
+			"tx.memo.type: MemoTypeMemoText",
+			`tx.memo.text: "pay \"rent\""`,
+		}))
+
+		Expect(lines).To(ContainElement("tx.operations.len: 14"))
+		Expect(lines).To(ContainElement("tx.operations[0].sourceAccount._present: false"))
+		Expect(lines).To(ContainElement("tx.operations[1].sourceAccount: " + issuer))
+		Expect(lines).To(ContainElement("tx.operations[1].body.paymentOp.amount: 10.5000000"))
+		Expect(lines).To(ContainElement("tx.operations[9].body.type: OperationTypeInflation"))
+		Expect(lines).To(ContainElement(`tx.operations[11].body.adminOp.opData: {"block":true}`))
+		Expect(lines).To(ContainElement("tx.operations[12].body.paymentReversalOp.paymentId: 42"))
+		Expect(lines).To(ContainElement("tx.operations[13].body.externalPaymentOp.asset: USD:" + issuer))
+		Expect(lines).To(ContainElement("operationFees.len: 2"))
+		Expect(lines).To(ContainElement("operationFees[1].fee.percentFee: 1.5000000"))
+	})
+
+	It("accepts edits, comments and blank lines", func() {
+		edited := strings.Replace(text,
+			"tx.operations[1].body.paymentOp.amount: 10.5000000",
+			"# reviewed\n\ntx.operations[1].body.paymentOp.amount: 12", 1)
+
+		var decoded TransactionEnvelope
+		Expect(UnmarshalTxRep(edited, &decoded)).To(Succeed())
+		Expect(decoded.Tx.Operations[1].Body.MustPaymentOp().Amount).To(BeEquivalentTo(120000000))
+	})
+
+	It("rejects missing, unknown and duplicate keys", func() {
+		var decoded TransactionEnvelope
+
+		missing := strings.Replace(text, "tx.fee: 100\n", "", 1)
+		Expect(UnmarshalTxRep(missing, &decoded)).To(MatchError("xdr: missing key tx.fee"))
+
+		Expect(UnmarshalTxRep(text+"tx.extra: 1\n", &decoded)).To(MatchError("xdr: unknown key tx.extra"))
+		Expect(UnmarshalTxRep(text+"tx.fee: 200\n", &decoded)).NotTo(Succeed())
+		Expect(UnmarshalTxRep("tx.fee 100", &decoded)).NotTo(Succeed())
+	})
+
+	It("rejects lengths larger than the input", func() {
+		var decoded TransactionEnvelope
+		huge := strings.Replace(text, "tx.operations.len: 14\n", "tx.operations.len: 999999999999\n", 1)
+		Expect(UnmarshalTxRep(huge, &decoded)).To(MatchError("xdr: invalid length tx.operations.len: 999999999999"))
+
+		long := strings.Replace(text, "operationFees.len: 2\n", "operationFees.len: 3\n", 1)
+		Expect(UnmarshalTxRep(long, &decoded)).NotTo(Succeed())
+	})
+})